@@ -0,0 +1,180 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/data-preservation-programs/filsigner-relayed/approval"
+	"github.com/data-preservation-programs/filsigner-relayed/cosign"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Handler implements the operator actions exposed on the admin socket
+type Handler interface {
+	ListTickets() ([]*approval.Ticket, error)
+	ApproveTicket(id string, note string) (*approval.Ticket, error)
+	RejectTicket(id string, note string) (*approval.Ticket, error)
+	SubmitApproval(signed cosign.SignedApproval) error
+}
+
+type decision struct {
+	Note string `json:"note"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		logging.Logger("admin").Errorw("failed to write response", "error", err)
+	}
+}
+
+func NewMux(handler Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/approvals", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+			return
+		}
+
+		tickets, err := handler.ListTickets()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, tickets)
+	})
+	mux.HandleFunc("/approvals/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+			return
+		}
+
+		// The path is /approvals/<ticket>/<approve|reject>
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/approvals/"), "/")
+		if len(parts) != 2 {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+			return
+		}
+
+		body := decision{}
+		if r.ContentLength != 0 {
+			err := json.NewDecoder(r.Body).Decode(&body)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+				return
+			}
+		}
+
+		var ticket *approval.Ticket
+		var err error
+		switch parts[1] {
+		case "approve":
+			ticket, err = handler.ApproveTicket(parts[0], body.Note)
+		case "reject":
+			ticket, err = handler.RejectTicket(parts[0], body.Note)
+		default:
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+			return
+		}
+
+		if errors.Is(err, approval.ErrTicketNotFound) {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, ticket)
+	})
+	mux.HandleFunc("/cosignatures", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+			return
+		}
+
+		signed := cosign.SignedApproval{}
+		err := json.NewDecoder(r.Body).Decode(&signed)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+
+		err = handler.SubmitApproval(signed)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, signed)
+	})
+	return mux
+}
+
+// Serve listens on the unix socket at socketPath until the context is done.
+// The socket is only accessible by the user running the server.
+func Serve(ctx context.Context, socketPath string, mux *http.ServeMux) error {
+	err := os.Remove(socketPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "failed to remove stale admin socket")
+	}
+
+	// The socket is created in a private directory and only moved in place once its permissions are restricted,
+	// so no other user can connect in between
+	dir, err := os.MkdirTemp(filepath.Dir(socketPath), ".admin-socket-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create admin socket directory")
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, "admin.sock")
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen on admin socket")
+	}
+	defer listener.Close()
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	err = os.Chmod(tmpPath, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to restrict admin socket permissions")
+	}
+
+	err = os.Rename(tmpPath, socketPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to move admin socket in place")
+	}
+	defer os.Remove(socketPath)
+
+	err = os.Remove(dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to remove admin socket directory")
+	}
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return errors.Wrap(err, "admin socket server failed")
+}
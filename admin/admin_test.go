@@ -0,0 +1,183 @@
+package admin
+
+import (
+	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/approval"
+	"github.com/data-preservation-programs/filsigner-relayed/cosign"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testHandler struct {
+	tickets   map[string]*approval.Ticket
+	submitted []cosign.SignedApproval
+}
+
+func (h *testHandler) ListTickets() ([]*approval.Ticket, error) {
+	return []*approval.Ticket{h.tickets["ticket"]}, nil
+}
+
+func (h *testHandler) decide(id string, status approval.Status, note string) (*approval.Ticket, error) {
+	ticket, ok := h.tickets[id]
+	if !ok {
+		return nil, approval.ErrTicketNotFound
+	}
+
+	if ticket.Status != approval.Pending {
+		return nil, errors.Errorf("ticket %s is already %s", id, ticket.Status)
+	}
+
+	ticket.Status = status
+	ticket.Note = note
+	return ticket, nil
+}
+
+func (h *testHandler) ApproveTicket(id string, note string) (*approval.Ticket, error) {
+	return h.decide(id, approval.Approved, note)
+}
+
+func (h *testHandler) RejectTicket(id string, note string) (*approval.Ticket, error) {
+	return h.decide(id, approval.Rejected, note)
+}
+
+func (h *testHandler) SubmitApproval(signed cosign.SignedApproval) error {
+	if signed.Approver == "" {
+		return errors.New("approval has no approver")
+	}
+
+	h.submitted = append(h.submitted, signed)
+	return nil
+}
+
+func serve(t *testing.T, handler Handler) string {
+	t.Helper()
+	socketPath := filepath.Join(t.TempDir(), "admin.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, socketPath, NewMux(handler))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		err := <-done
+		if err != nil {
+			t.Errorf("err is not null: %v", err)
+		}
+	})
+
+	for i := 0; i < 100; i++ {
+		_, err := os.Stat(socketPath)
+		if err == nil {
+			return socketPath
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("admin socket was not created")
+	return ""
+}
+
+func TestServe(t *testing.T) {
+	handler := &testHandler{tickets: map[string]*approval.Ticket{
+		"ticket": {ID: "ticket", Status: approval.Pending},
+	}}
+	socketPath := serve(t, handler)
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o600 {
+		t.Fatalf("unexpected admin socket mode %v", info.Mode())
+	}
+
+	// Only the socket is left in the directory
+	entries, err := os.ReadDir(filepath.Dir(socketPath))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("unexpected admin socket directory entries %v", entries)
+	}
+
+	ctx := context.Background()
+	client := NewClient(socketPath)
+	tickets, err := client.ListTickets(ctx)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if len(tickets) != 1 || tickets[0].ID != "ticket" {
+		t.Fatalf("unexpected tickets %v", tickets)
+	}
+
+	ticket, err := client.ApproveTicket(ctx, "ticket", "looks good")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if ticket.Status != approval.Approved || ticket.Note != "looks good" {
+		t.Fatalf("unexpected ticket %v", ticket)
+	}
+
+	_, err = client.RejectTicket(ctx, "ticket", "")
+	if err == nil || !strings.Contains(err.Error(), "already approved") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = client.RejectTicket(ctx, "missing", "")
+	if err == nil || !strings.Contains(err.Error(), approval.ErrTicketNotFound.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = client.SubmitApproval(ctx, cosign.SignedApproval{Approver: "approver", Payload: []byte("{}")})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if len(handler.submitted) != 1 || handler.submitted[0].Approver != "approver" {
+		t.Fatalf("unexpected submitted approvals %v", handler.submitted)
+	}
+
+	err = client.SubmitApproval(ctx, cosign.SignedApproval{})
+	if err == nil {
+		t.Fatalf("expected invalid approval to be refused")
+	}
+}
+
+func TestServeReplacesStaleSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "admin.sock")
+	err := os.WriteFile(socketPath, nil, 0o666)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, socketPath, NewMux(&testHandler{}))
+	}()
+
+	for i := 0; i < 100; i++ {
+		info, err := os.Stat(socketPath)
+		if err == nil && info.Mode()&os.ModeSocket != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	err = <-done
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(socketPath))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected admin socket to be removed on shutdown, found %v", entries)
+	}
+}
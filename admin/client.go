@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/data-preservation-programs/filsigner-relayed/approval"
+	"github.com/data-preservation-programs/filsigner-relayed/cosign"
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+)
+
+// Client talks to the admin socket of a running filsigner server
+type Client struct {
+	http *http.Client
+}
+
+func NewClient(socketPath string) *Client {
+	return &Client{
+		http: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body any, result any) error {
+	reader := bytes.NewReader(nil)
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode request")
+		}
+		reader = bytes.NewReader(content)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, "http://admin"+path, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to call admin socket")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		failure := errorResponse{}
+		err = json.NewDecoder(response.Body).Decode(&failure)
+		if err != nil {
+			return errors.Errorf("admin socket returned status %d", response.StatusCode)
+		}
+
+		return errors.New(failure.Error)
+	}
+
+	return errors.Wrap(json.NewDecoder(response.Body).Decode(result), "failed to decode response")
+}
+
+func (c *Client) ListTickets(ctx context.Context) ([]*approval.Ticket, error) {
+	var tickets []*approval.Ticket
+	err := c.do(ctx, http.MethodGet, "/approvals", nil, &tickets)
+	return tickets, err
+}
+
+func (c *Client) ApproveTicket(ctx context.Context, id string, note string) (*approval.Ticket, error) {
+	ticket := new(approval.Ticket)
+	err := c.do(ctx, http.MethodPost, "/approvals/"+id+"/approve", decision{Note: note}, ticket)
+	return ticket, err
+}
+
+func (c *Client) RejectTicket(ctx context.Context, id string, note string) (*approval.Ticket, error) {
+	ticket := new(approval.Ticket)
+	err := c.do(ctx, http.MethodPost, "/approvals/"+id+"/reject", decision{Note: note}, ticket)
+	return ticket, err
+}
+
+func (c *Client) SubmitApproval(ctx context.Context, signed cosign.SignedApproval) error {
+	return c.do(ctx, http.MethodPost, "/cosignatures", signed, &cosign.SignedApproval{})
+}
@@ -0,0 +1,173 @@
+package approval
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	Pending  Status = "pending"
+	Approved Status = "approved"
+	Rejected Status = "rejected"
+)
+
+var ErrTicketNotFound = errors.New("ticket not found")
+
+// Ticket is a proposal parked for manual approval by an operator
+type Ticket struct {
+	ID            string    `json:"id"`
+	Status        Status    `json:"status"`
+	Requester     string    `json:"requester"`
+	Reasons       []string  `json:"reasons"`
+	Client        string    `json:"client"`
+	Provider      string    `json:"provider"`
+	PieceCID      string    `json:"pieceCid"`
+	PieceSize     uint64    `json:"pieceSize"`
+	Proposal      []byte    `json:"proposal"`
+	DealUUID      string    `json:"dealUuid,omitempty"`
+	ActorsVersion int       `json:"actorsVersion,omitempty"`
+	Signature     []byte    `json:"signature,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	Created       time.Time `json:"created"`
+	Decided       time.Time `json:"decided,omitempty"`
+}
+
+// Queue is the persistent pending approval queue, storing one JSON file per ticket
+type Queue struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func NewQueue(dir string) (*Queue, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create approval queue directory")
+	}
+
+	return &Queue{dir: dir}, nil
+}
+
+func (q *Queue) path(id string) string {
+	return filepath.Join(q.dir, id+".json")
+}
+
+func (q *Queue) Get(id string) (*Ticket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.get(id)
+}
+
+func (q *Queue) get(id string) (*Ticket, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, ErrTicketNotFound
+	}
+
+	content, err := os.ReadFile(q.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTicketNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read ticket")
+	}
+
+	ticket := new(Ticket)
+	err = json.Unmarshal(content, ticket)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode ticket")
+	}
+
+	return ticket, nil
+}
+
+// Put stores the ticket, replacing any previous ticket with the same ID
+func (q *Queue) Put(ticket *Ticket) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.put(ticket)
+}
+
+func (q *Queue) put(ticket *Ticket) error {
+	content, err := json.MarshalIndent(ticket, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode ticket")
+	}
+
+	tmp := q.path(ticket.ID) + ".tmp"
+	err = os.WriteFile(tmp, content, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write ticket")
+	}
+
+	return errors.Wrap(os.Rename(tmp, q.path(ticket.ID)), "failed to write ticket")
+}
+
+// Decide moves a pending ticket to the approved or rejected state.
+// The sign function is only called for approvals and its signature is stored with the ticket.
+func (q *Queue) Decide(id string, status Status, note string, sign func(*Ticket) ([]byte, error)) (*Ticket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ticket, err := q.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Status != Pending {
+		return nil, errors.Errorf("ticket %s is already %s", id, ticket.Status)
+	}
+
+	if status == Approved {
+		ticket.Signature, err = sign(ticket)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ticket.Status = status
+	ticket.Note = note
+	ticket.Decided = time.Now().UTC()
+	err = q.put(ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
+
+// List returns all tickets ordered by creation time
+func (q *Queue) List() ([]*Ticket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list approval queue")
+	}
+
+	tickets := make([]*Ticket, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		ticket, err := q.get(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	sort.Slice(tickets, func(i, j int) bool {
+		return tickets[i].Created.Before(tickets[j].Created)
+	})
+	return tickets, nil
+}
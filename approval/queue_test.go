@@ -0,0 +1,93 @@
+package approval
+
+import (
+	"github.com/pkg/errors"
+	"testing"
+	"time"
+)
+
+func TestQueueDecide(t *testing.T) {
+	queue, err := NewQueue(t.TempDir())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	created := time.Now().UTC()
+	for i, id := range []string{"second", "first", "third"} {
+		err = queue.Put(&Ticket{ID: id, Status: Pending, Created: created.Add(time.Duration([]int{1, 0, 2}[i]) * time.Second)})
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+	}
+
+	tickets, err := queue.List()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if len(tickets) != 3 || tickets[0].ID != "first" || tickets[1].ID != "second" || tickets[2].ID != "third" {
+		t.Fatalf("unexpected tickets %v", tickets)
+	}
+
+	sign := func(ticket *Ticket) ([]byte, error) {
+		return []byte("signature of " + ticket.ID), nil
+	}
+
+	// A failed signature leaves the ticket pending
+	_, err = queue.Decide("first", Approved, "", func(*Ticket) ([]byte, error) {
+		return nil, errors.New("wallet is locked")
+	})
+	if err == nil {
+		t.Fatalf("expected failed signature to fail the approval")
+	}
+
+	ticket, err := queue.Get("first")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if ticket.Status != Pending || ticket.Signature != nil {
+		t.Fatalf("unexpected ticket %v", ticket)
+	}
+
+	ticket, err = queue.Decide("first", Approved, "looks good", sign)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if ticket.Status != Approved || string(ticket.Signature) != "signature of first" || ticket.Note != "looks good" || ticket.Decided.IsZero() {
+		t.Fatalf("unexpected ticket %v", ticket)
+	}
+
+	// Rejections do not sign
+	ticket, err = queue.Decide("second", Rejected, "too large", func(*Ticket) ([]byte, error) {
+		t.Fatalf("expected rejection not to sign")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if ticket.Status != Rejected || ticket.Signature != nil {
+		t.Fatalf("unexpected ticket %v", ticket)
+	}
+
+	// Decisions are persisted and final
+	stored, err := queue.Get("first")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if stored.Status != Approved || string(stored.Signature) != "signature of first" {
+		t.Fatalf("unexpected stored ticket %v", stored)
+	}
+
+	for _, id := range []string{"first", "second"} {
+		_, err = queue.Decide(id, Rejected, "", sign)
+		if err == nil {
+			t.Fatalf("expected decided ticket %s to stay decided", id)
+		}
+	}
+
+	for _, id := range []string{"missing", "", "../first", "first.json"} {
+		_, err = queue.Decide(id, Approved, "", sign)
+		if !errors.Is(err, ErrTicketNotFound) {
+			t.Fatalf("expected ticket %q not to be found, got %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package approval
+
+import (
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+)
+
+// Rules decide which proposals are parked for manual approval instead of being signed automatically
+type Rules struct {
+	// PieceSizeAbove requires approval for pieces larger than this size. Zero disables the rule.
+	PieceSizeAbove abi.PaddedPieceSize
+	// PricePerEpochAbove requires approval for storage prices per epoch above this amount. Nil disables the rule.
+	PricePerEpochAbove *big.Int
+	// NewProviders requires approval for providers that have never been signed for before
+	NewProviders bool
+}
+
+func (r Rules) Enabled() bool {
+	return r.PieceSizeAbove > 0 || r.PricePerEpochAbove != nil || r.NewProviders
+}
+
+// Check returns the reasons why the proposal requires manual approval, or nothing if it can be signed automatically
+// @param knownProvider reports whether the provider has been signed for before
+func (r Rules) Check(proposal *filmarket.DealProposal, knownProvider func(address.Address) bool) []string {
+	var reasons []string
+	if r.PieceSizeAbove > 0 && proposal.PieceSize > r.PieceSizeAbove {
+		reasons = append(reasons, fmt.Sprintf("piece size %d is above %d", proposal.PieceSize, r.PieceSizeAbove))
+	}
+
+	if r.PricePerEpochAbove != nil && !proposal.StoragePricePerEpoch.Nil() &&
+		proposal.StoragePricePerEpoch.GreaterThan(*r.PricePerEpochAbove) {
+		reasons = append(reasons, fmt.Sprintf("storage price per epoch %s is above %s", proposal.StoragePricePerEpoch, r.PricePerEpochAbove))
+	}
+
+	if r.NewProviders && !knownProvider(proposal.Provider) {
+		reasons = append(reasons, "provider "+proposal.Provider.String()+" has not been signed for before")
+	}
+
+	return reasons
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"os"
+	"sync"
+	"time"
+)
+
+type Event string
+
+const (
+	Signed           Event = "signed"
+	Rejected         Event = "rejected"
+	PendingApproval  Event = "pending_approval"
+	Approved         Event = "approved"
+	ApprovalRejected Event = "approval_rejected"
+	ManifestLoaded   Event = "manifest_loaded"
+)
+
+// Record is a single entry of the audit trail, written as one JSON line
+type Record struct {
+	Time              time.Time `json:"time"`
+	Event             Event     `json:"event"`
+	Requester         string    `json:"requester,omitempty"`
+	Code              string    `json:"code,omitempty"`
+	Message           string    `json:"message,omitempty"`
+	Client            string    `json:"client,omitempty"`
+	Provider          string    `json:"provider,omitempty"`
+	PieceCID          string    `json:"pieceCid,omitempty"`
+	PieceSize         uint64    `json:"pieceSize,omitempty"`
+	VerifiedDeal      bool      `json:"verifiedDeal,omitempty"`
+	ProposalCID       string    `json:"proposalCid,omitempty"`
+	Ticket            string    `json:"ticket,omitempty"`
+	MessageType       string    `json:"messageType,omitempty"`
+	DealUUID          string    `json:"dealUuid,omitempty"`
+	VerifiedClient    string    `json:"verifiedClient,omitempty"`
+	RemovalProposalID *uint64   `json:"removalProposalId,omitempty"`
+	ActorsVersion     int       `json:"actorsVersion,omitempty"`
+	ManifestVersion   string    `json:"manifestVersion,omitempty"`
+}
+
+// Log is an append-only audit trail backed by a JSON lines file.
+// A nil Log discards all records.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens the audit log at the given path, creating it if it does not exist
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open audit log")
+	}
+
+	return &Log{file: file}, nil
+}
+
+func (l *Log) Append(record Record) error {
+	if l == nil {
+		return nil
+	}
+
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal audit record")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(line, '\n'))
+	if err != nil {
+		return errors.Wrap(err, "failed to write audit record")
+	}
+
+	return errors.Wrap(l.file.Sync(), "failed to sync audit log")
+}
+
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	return errors.Wrap(l.file.Close(), "failed to close audit log")
+}
+
+// Read calls fn for every record in the audit log at the given path, in the order they were written.
+// A missing audit log is treated as empty.
+func Read(path string, fn func(Record) error) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to open audit log")
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		record := Record{}
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode audit record")
+		}
+
+		err = fn(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(scanner.Err(), "failed to read audit log")
+}
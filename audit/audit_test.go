@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"github.com/pkg/errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	err := Read(path, func(Record) error {
+		t.Fatalf("expected missing audit log to be empty")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	// Records are appended to the existing log when it is opened again
+	for _, event := range []Event{PendingApproval, Approved} {
+		log, err := Open(path)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		err = log.Append(Record{Event: event, Ticket: "ticket"})
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		err = log.Close()
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+	}
+
+	var records []Record
+	err = Read(path, func(record Record) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if len(records) != 2 || records[0].Event != PendingApproval || records[1].Event != Approved {
+		t.Fatalf("unexpected records %v", records)
+	}
+	if records[0].Time.IsZero() || records[1].Time.Before(records[0].Time) {
+		t.Fatalf("unexpected record times %v", records)
+	}
+
+	// Replay stops at the first error of the callback
+	stop := errors.New("stop")
+	count := 0
+	err = Read(path, func(Record) error {
+		count++
+		return stop
+	})
+	if !errors.Is(err, stop) || count != 1 {
+		t.Fatalf("unexpected replay result %v after %d records", err, count)
+	}
+
+	var discard *Log
+	if discard.Append(Record{Event: Signed}) != nil || discard.Close() != nil {
+		t.Fatalf("expected nil log to discard records")
+	}
+}
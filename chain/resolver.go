@@ -0,0 +1,60 @@
+package chain
+
+import (
+	"context"
+	"github.com/filecoin-project/go-address"
+	"github.com/pkg/errors"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+const DefaultEndpoint = "https://api.node.glif.io/rpc/v0"
+
+// Resolver translates between the ID address and the key address of an account
+type Resolver interface {
+	// LookupID returns the ID address of the account
+	LookupID(ctx context.Context, addr address.Address) (address.Address, error)
+	// AccountKey returns the key address (f1/f3) of the account
+	AccountKey(ctx context.Context, addr address.Address) (address.Address, error)
+}
+
+// RPCResolver resolves addresses through the Lotus JSON-RPC API
+type RPCResolver struct {
+	client jsonrpc.RPCClient
+}
+
+// NewRPCResolver creates a resolver for the Lotus JSON-RPC endpoint
+// @param token the API token, if the endpoint requires one
+func NewRPCResolver(endpoint string, token string) *RPCResolver {
+	opts := &jsonrpc.RPCClientOpts{}
+	if token != "" {
+		opts.CustomHeaders = map[string]string{"Authorization": "Bearer " + token}
+	}
+
+	return &RPCResolver{client: jsonrpc.NewClientWithOpts(endpoint, opts)}
+}
+
+func (r *RPCResolver) call(ctx context.Context, method string, addr address.Address) (address.Address, error) {
+	var resolved string
+	err := r.client.CallFor(ctx, &resolved, method, addr.String(), nil)
+	if err != nil {
+		return address.Undef, errors.Wrapf(err, "failed to call %s", method)
+	}
+
+	return address.NewFromString(resolved)
+}
+
+func (r *RPCResolver) LookupID(ctx context.Context, addr address.Address) (address.Address, error) {
+	if addr.Protocol() == address.ID {
+		return addr, nil
+	}
+
+	return r.call(ctx, "Filecoin.StateLookupID", addr)
+}
+
+func (r *RPCResolver) AccountKey(ctx context.Context, addr address.Address) (address.Address, error) {
+	if addr.Protocol() == address.SECP256K1 || addr.Protocol() == address.BLS {
+		return addr, nil
+	}
+
+	return r.call(ctx, "Filecoin.StateAccountKey", addr)
+}
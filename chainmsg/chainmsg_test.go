@@ -0,0 +1,134 @@
+package chainmsg
+
+import (
+	"bytes"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	market, err := address.NewIDAddress(5)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	rule, err := ParseRule("f05:2:1000")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if rule.To != market || rule.Method != 2 || !rule.MaxValue.Equals(big.NewInt(1000)) {
+		t.Fatalf("unexpected rule: %v", rule)
+	}
+
+	msg := &Message{
+		To:         market,
+		From:       address.TestAddress,
+		Value:      big.NewInt(1000),
+		GasFeeCap:  big.Zero(),
+		GasPremium: big.Zero(),
+		Method:     abi.MethodNum(2),
+	}
+	err = Check([]Rule{rule}, msg)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	msg.Value = big.NewInt(1001)
+	if Check([]Rule{rule}, msg) == nil {
+		t.Fatal("expected an error for a value above the cap")
+	}
+
+	// The fee is capped at the default max fee unless the rule sets one
+	msg.Value = big.Zero()
+	msg.GasLimit = 1_000_000
+	msg.GasFeeCap = big.Div(DefaultMaxFee, big.NewInt(msg.GasLimit))
+	err = Check([]Rule{rule}, msg)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	msg.GasFeeCap = big.Add(msg.GasFeeCap, big.NewInt(1))
+	if Check([]Rule{rule}, msg) == nil {
+		t.Fatal("expected an error for a fee above the cap")
+	}
+
+	capped, err := ParseRule("f05:2:0:1000")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if !capped.MaxValue.IsZero() || !capped.MaxFee.Equals(big.NewInt(1000)) || capped.String() != market.String()+":2:0:1000" {
+		t.Fatalf("unexpected rule: %v", capped)
+	}
+
+	msg.GasLimit = 10
+	msg.GasFeeCap = big.NewInt(100)
+	err = Check([]Rule{capped}, msg)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	msg.GasLimit = 11
+	if Check([]Rule{capped}, msg) == nil {
+		t.Fatal("expected an error for a fee above the cap of the rule")
+	}
+
+	msg.GasLimit = 10
+	msg.GasPremium = big.NewInt(101)
+	if Check([]Rule{capped}, msg) == nil {
+		t.Fatal("expected an error for a gas premium above the fee cap")
+	}
+
+	msg.GasPremium = big.Zero()
+	msg.GasLimit = -1
+	if Check([]Rule{capped}, msg) == nil {
+		t.Fatal("expected an error for a negative gas limit")
+	}
+
+	msg.GasLimit = 0
+	msg.Method = 3
+	if Check([]Rule{rule}, msg) == nil {
+		t.Fatal("expected an error for a method that is not allowed")
+	}
+
+	for _, value := range []string{"f05", "f05:x", "f05:2:1:x", "f05:2:1:2:3", "nope:2"} {
+		_, err = ParseRule(value)
+		if err == nil {
+			t.Fatalf("expected an error for %s", value)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	msg := &Message{
+		To:         address.TestAddress,
+		From:       address.TestAddress2,
+		Nonce:      7,
+		Value:      big.NewInt(1),
+		GasLimit:   1000,
+		GasFeeCap:  big.NewInt(100),
+		GasPremium: big.NewInt(10),
+		Method:     2,
+		Params:     []byte{0x80},
+	}
+	data, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if decoded.Nonce != 7 || decoded.From != address.TestAddress2 || !decoded.GasFeeCap.Equals(big.NewInt(100)) {
+		t.Fatalf("unexpected message: %v", decoded)
+	}
+
+	// A value with a leading zero magnitude byte decodes, but does not have the CID of the signed message
+	nonCanonical := bytes.Replace(data, []byte{0x42, 0x00, 0x01}, []byte{0x43, 0x00, 0x00, 0x01}, 1)
+	_, err = Decode(nonCanonical)
+	if err == nil {
+		t.Fatal("expected an error for a non canonical message")
+	}
+}
@@ -0,0 +1,82 @@
+package chainmsg
+
+import (
+	"bytes"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+)
+
+//go:generate go run github.com/hannahhoward/cbor-gen-for Message SignedMessage
+
+// Message is a Filecoin chain message, encoded the same way as the Lotus types.Message
+type Message struct {
+	Version    uint64
+	To         address.Address
+	From       address.Address
+	Nonce      uint64
+	Value      abi.TokenAmount
+	GasLimit   int64
+	GasFeeCap  abi.TokenAmount
+	GasPremium abi.TokenAmount
+	Method     abi.MethodNum
+	Params     []byte
+}
+
+// SignedMessage is a chain message with the signature of its sender, encoded the same way as the Lotus types.SignedMessage
+type SignedMessage struct {
+	Message   Message
+	Signature filcrypto.Signature
+}
+
+// Decode decodes a chain message, which must be encoded canonically so that its CID is the one that gets signed
+func Decode(data []byte) (*Message, error) {
+	msg := new(Message)
+	err := msg.UnmarshalCBOR(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode chain message")
+	}
+
+	encoded, err := msg.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(encoded, data) {
+		return nil, errors.New("chain message remarshalled does not match the original message bytes")
+	}
+
+	return msg, nil
+}
+
+func (m *Message) Bytes() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := m.MarshalCBOR(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode chain message")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Cid returns the CID of the message, whose bytes are what the sender signs as in Lotus
+func (m *Message) Cid() (cid.Cid, error) {
+	data, err := m.Bytes()
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return Cid(data)
+}
+
+// Cid returns the CID of the encoded chain message
+func Cid(data []byte) (cid.Cid, error) {
+	msgCid, err := abi.CidBuilder.Sum(data)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "failed to compute message cid")
+	}
+
+	return msgCid, nil
+}
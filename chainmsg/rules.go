@@ -0,0 +1,113 @@
+package chainmsg
+
+import (
+	"fmt"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxFee is the fee cap of rules that do not set one, the default max fee of Lotus of 0.07 FIL
+var DefaultMaxFee = big.NewInt(70_000_000_000_000_000)
+
+// Rule allows sending messages that call a method of an actor, with at most MaxValue attached
+// and at most MaxFee paid in gas
+type Rule struct {
+	// To is the recipient actor. It is compared as given, so rules for builtin actors use their ID addresses.
+	To       address.Address
+	Method   abi.MethodNum
+	MaxValue abi.TokenAmount
+	// MaxFee caps GasFeeCap*GasLimit, the most the message can cost in gas. It is DefaultMaxFee if it is not set.
+	MaxFee abi.TokenAmount
+}
+
+func (r Rule) maxFee() abi.TokenAmount {
+	if r.MaxFee.Nil() {
+		return DefaultMaxFee
+	}
+
+	return r.MaxFee
+}
+
+// String returns the rule in the format ParseRule decodes
+func (r Rule) String() string {
+	return fmt.Sprintf("%s:%d:%s:%s", r.To, r.Method, r.MaxValue, r.maxFee())
+}
+
+// ParseRule decodes a rule given as <to>:<method>[:<max value in attoFIL>[:<max fee in attoFIL>]].
+// No value may be attached if the value cap is not set, and the fee cap is DefaultMaxFee if it is not set.
+func ParseRule(value string) (Rule, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 4 {
+		return Rule{}, errors.Errorf("rule %s must be given as <to>:<method>[:<max value>[:<max fee>]]", value)
+	}
+
+	to, err := address.NewFromString(parts[0])
+	if err != nil {
+		return Rule{}, errors.Wrapf(err, "failed to decode recipient %s", parts[0])
+	}
+
+	method, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return Rule{}, errors.Wrapf(err, "failed to decode method %s", parts[1])
+	}
+
+	rule := Rule{To: to, Method: abi.MethodNum(method), MaxValue: big.Zero()}
+	if len(parts) > 2 {
+		rule.MaxValue, err = big.FromString(parts[2])
+		if err != nil {
+			return Rule{}, errors.Wrapf(err, "failed to decode max value %s", parts[2])
+		}
+	}
+
+	if len(parts) > 3 {
+		rule.MaxFee, err = big.FromString(parts[3])
+		if err != nil {
+			return Rule{}, errors.Wrapf(err, "failed to decode max fee %s", parts[3])
+		}
+	}
+
+	return rule, nil
+}
+
+// Check returns an error unless one of the rules allows the message
+func Check(rules []Rule, msg *Message) error {
+	if msg.Version != 0 {
+		return errors.Errorf("unsupported message version %d", msg.Version)
+	}
+
+	if msg.Value.Int == nil || msg.Value.Sign() < 0 {
+		return errors.New("message value must not be negative")
+	}
+
+	if msg.GasLimit < 0 || msg.GasFeeCap.Int == nil || msg.GasFeeCap.Sign() < 0 || msg.GasPremium.Int == nil || msg.GasPremium.Sign() < 0 {
+		return errors.New("message gas limit, fee cap and premium must not be negative")
+	}
+
+	if msg.GasPremium.GreaterThan(msg.GasFeeCap) {
+		return errors.Errorf("message gas premium %s is above the gas fee cap %s", msg.GasPremium, msg.GasFeeCap)
+	}
+
+	for _, rule := range rules {
+		if rule.To != msg.To || rule.Method != msg.Method {
+			continue
+		}
+
+		if msg.Value.GreaterThan(rule.MaxValue) {
+			return errors.Errorf("message value %s to %s exceeds the cap of %s", msg.Value, msg.To, rule.MaxValue)
+		}
+
+		// The fee cap bounds the base fee and premium paid per unit of gas, so this is the most the message can burn
+		fee := big.Mul(msg.GasFeeCap, big.NewInt(msg.GasLimit))
+		if fee.GreaterThan(rule.maxFee()) {
+			return errors.Errorf("message fee of up to %s to %s exceeds the cap of %s", fee, msg.To, rule.maxFee())
+		}
+
+		return nil
+	}
+
+	return errors.Errorf("method %d of %s is not allowed", msg.Method, msg.To)
+}
@@ -8,8 +8,14 @@ import (
 type RequestError struct {
 	StatusCode model.StatusCode
 	Message    string
+	Ticket     string
+	// Mismatch locates the field that did not round-trip, for ProposalRemarshalMismatch errors
+	Mismatch *model.RemarshalMismatch
 }
 
 func (e *RequestError) Error() string {
+	if e.Ticket != "" {
+		return fmt.Sprintf("Request failed with status code %d (%s), ticket %s: %s", e.StatusCode, model.StatusCodeString[e.StatusCode], e.Ticket, e.Message)
+	}
 	return fmt.Sprintf("Request failed with status code %d (%s): %s", e.StatusCode, model.StatusCodeString[e.StatusCode], e.Message)
 }
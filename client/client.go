@@ -1,36 +1,56 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/chain"
+	"github.com/data-preservation-programs/filsigner-relayed/chainmsg"
 	"github.com/data-preservation-programs/filsigner-relayed/config"
+	"github.com/data-preservation-programs/filsigner-relayed/message"
 	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/verify"
+	"github.com/filecoin-project/go-address"
 	cborutil "github.com/filecoin-project/go-cbor-util"
 	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/filecoin-project/go-state-types/builtin/v9/verifreg"
 	filcrypto "github.com/filecoin-project/go-state-types/crypto"
-	"github.com/jsign/go-filsigner/wallet"
+	filnetwork "github.com/filecoin-project/go-state-types/network"
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/core/protocol"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
 	"time"
 )
 
 type Client struct {
 	host   host.Host
 	relays []peer.AddrInfo
+	// Resolver resolves ID client addresses to key addresses to verify the returned signatures against.
+	// If it is nil, the RPC endpoint of Network is used.
+	Resolver chain.Resolver
+	// HedgeDelay is how long SignProposalWithFailover waits for a signer peer before also asking the next one.
+	// Zero means the next peer is only asked after the previous one failed.
+	HedgeDelay time.Duration
+	// NetworkVersion is sent with the sign protocol requests so that deal proposals are decoded with the market actor
+	// of the network version. Zero leaves it to the signer.
+	NetworkVersion filnetwork.Version
+	// Network selects the protocol IDs of the signer network. The zero value is mainnet.
+	Network config.Network
 }
 
-func (c Client) SignProposal(ctx context.Context, dest peer.ID, proposal filmarket.DealProposal) (*filcrypto.Signature, error) {
+func (c Client) addRelayedAddrs(dest peer.ID) error {
 	targetAddrs := make([]ma.Multiaddr, 0)
 	for _, relay := range c.relays {
 		for _, addr := range relay.Addrs {
 			targetAddr, err := ma.NewMultiaddr(addr.String() + "/p2p/" + relay.ID.String() + "/p2p-circuit/p2p/" + dest.String())
 			if err != nil {
-				return nil, errors.Wrap(err, "failed to create target relayed multiaddr")
+				return errors.Wrap(err, "failed to create target relayed multiaddr")
 			}
 
 			targetAddrs = append(targetAddrs, targetAddr)
@@ -38,16 +58,20 @@ func (c Client) SignProposal(ctx context.Context, dest peer.ID, proposal filmark
 	}
 
 	c.host.Peerstore().AddAddrs(dest, targetAddrs, peerstore.PermanentAddrTTL)
+	return nil
+}
 
-	// Marshal and send out the proposal
-	proposalBytes, err := cborutil.Dump(&proposal)
+// exchange sends the payload to the destination over the given protocol of the client network through the relays
+// and reads back the response
+func (c Client) exchange(ctx context.Context, dest peer.ID, protocolName protocol.ID, payload []byte, response cbg.CBORUnmarshaler) error {
+	err := c.addRelayedAddrs(dest)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshall proposal")
+		return err
 	}
 
-	stream, err := c.host.NewStream(network.WithUseTransient(ctx, "signproposal"), dest, config.ProtocolName)
+	stream, err := c.host.NewStream(network.WithUseTransient(ctx, "signproposal"), dest, c.Network.Protocol(protocolName))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to open stream")
+		return errors.Wrap(err, "failed to open stream")
 	}
 
 	defer stream.Close()
@@ -56,42 +80,365 @@ func (c Client) SignProposal(ctx context.Context, dest peer.ID, proposal filmark
 		defer stream.SetDeadline(time.Time{})
 	}
 
-	_, err = stream.Write(proposalBytes)
+	_, err = stream.Write(payload)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to write proposal to stream")
+		return errors.Wrap(err, "failed to write request to stream")
 	}
 	stream.CloseWrite()
 
-	response := new(model.SignerResponse)
 	err = cborutil.ReadCborRPC(stream, response)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal response")
+		return errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	return nil
+}
+
+// request sends a signing request and turns unsuccessful responses into a RequestError
+func (c Client) request(ctx context.Context, dest peer.ID, protocolName protocol.ID, payload []byte) (*model.SignerResponse, error) {
+	response := new(model.SignerResponse)
+	err := c.exchange(ctx, dest, protocolName, payload, response)
+	if err != nil {
+		return nil, err
 	}
 
 	if response.Code != model.Success {
 		return nil, &RequestError{
 			StatusCode: response.Code,
 			Message:    response.Message,
+			Ticket:     response.Ticket,
+			Mismatch:   response.Mismatch,
+		}
+	}
+
+	return response, nil
+}
+
+// Info asks the signer which wallets it holds, which protocols and message types it supports and what its policy limits are
+func (c Client) Info(ctx context.Context, dest peer.ID) (*model.SignerInfo, error) {
+	info := new(model.SignerInfo)
+	err := c.exchange(ctx, dest, config.InfoProtocolName, nil, info)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Code != model.Success {
+		return nil, &RequestError{
+			StatusCode: info.Code,
+			Message:    info.Message,
+		}
+	}
+
+	return info, nil
+}
+
+// Ping checks that the signer is reachable and returns its status along with the round trip time of the request
+func (c Client) Ping(ctx context.Context, dest peer.ID) (*model.PingResponse, time.Duration, error) {
+	err := c.addRelayedAddrs(dest)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = c.host.Connect(network.WithUseTransient(ctx, "ping"), peer.AddrInfo{ID: dest})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to connect to signer")
+	}
+
+	response := new(model.PingResponse)
+	start := time.Now()
+	err = c.exchange(ctx, dest, config.PingProtocolName, nil, response)
+	rtt := time.Since(start)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if response.Code != model.Success {
+		return nil, 0, &RequestError{
+			StatusCode: response.Code,
+			Message:    response.Message,
 		}
 	}
 
+	return response, rtt, nil
+}
+
+// RelayPingResult is the outcome of pinging the signer through a single relay
+type RelayPingResult struct {
+	Relay       peer.ID
+	ConnectTime time.Duration
+	RTT         time.Duration
+	Status      *model.PingResponse
+	Err         error
+}
+
+// PingRelays pings the signer through each relay separately, to tell which relay paths are usable.
+// Existing connections to the signer are closed before each relay is tried.
+// @param timeout the timeout for each relay path
+func (c Client) PingRelays(ctx context.Context, dest peer.ID, timeout time.Duration) []RelayPingResult {
+	results := make([]RelayPingResult, len(c.relays))
+	for i, relay := range c.relays {
+		results[i] = c.pingRelay(ctx, dest, relay, timeout)
+	}
+
+	c.host.Peerstore().ClearAddrs(dest)
+	return results
+}
+
+func (c Client) pingRelay(ctx context.Context, dest peer.ID, relay peer.AddrInfo, timeout time.Duration) RelayPingResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// The copy keeps the network and resolver of the client, and only knows the one relay
+	single := c
+	single.relays = []peer.AddrInfo{relay}
+	result := RelayPingResult{Relay: relay.ID}
+	c.host.Network().ClosePeer(dest)
+	c.host.Peerstore().ClearAddrs(dest)
+
+	err := single.addRelayedAddrs(dest)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	start := time.Now()
+	err = c.host.Connect(network.WithUseTransient(ctx, "ping"), peer.AddrInfo{ID: dest})
+	result.ConnectTime = time.Since(start)
+	if err != nil {
+		result.Err = errors.Wrap(err, "failed to connect to signer")
+		return result
+	}
+
+	result.Status, result.RTT, result.Err = single.Ping(ctx, dest)
+	return result
+}
+
+// resolver returns the Resolver of the client, or a resolver for the RPC endpoint of its network
+func (c Client) resolver() chain.Resolver {
+	if c.Resolver != nil {
+		return c.Resolver
+	}
+
+	endpoint := c.Network.RPCEndpoint
+	if endpoint == "" {
+		endpoint = chain.DefaultEndpoint
+	}
+
+	return chain.NewRPCResolver(endpoint, "")
+}
+
+func (c Client) verifySignature(ctx context.Context, proposal filmarket.DealProposal, proposalBytes []byte, signatureBytes []byte) (*filcrypto.Signature, error) {
 	signature := new(filcrypto.Signature)
+	err := signature.UnmarshalBinary(signatureBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal response signature")
+	}
 
 	// Verify the signature
-	valid, err := wallet.WalletVerify(proposal.Client, proposalBytes, response.Signature)
+	_, err = verify.Signature(ctx, c.resolver(), proposal.Client, proposalBytes, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+// SignProposal requests the signature of the proposal from the signer peer.
+// If the signer parks the proposal for manual approval, a RequestError with the PendingApproval status code
+// and the ticket to poll with PollTicket is returned.
+func (c Client) SignProposal(ctx context.Context, dest peer.ID, proposal filmarket.DealProposal) (*filcrypto.Signature, error) {
+	// Marshal and send out the proposal
+	proposalBytes, err := cborutil.Dump(&proposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshall proposal")
+	}
+
+	response, err := c.request(ctx, dest, config.ProtocolName, proposalBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.verifySignature(ctx, proposal, proposalBytes, response.Signature)
+}
+
+// PollTicket checks a proposal parked for manual approval and returns its signature once approved.
+// While the proposal is still waiting, a RequestError with the PendingApproval status code is returned.
+// @param proposal the proposal the ticket was issued for, used to verify the signature
+func (c Client) PollTicket(ctx context.Context, dest peer.ID, ticket string, proposal filmarket.DealProposal) (*filcrypto.Signature, error) {
+	proposalBytes, err := cborutil.Dump(&proposal)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to verify signature")
+		return nil, errors.Wrap(err, "failed to marshall proposal")
 	}
 
-	if !valid {
-		return nil, errors.New("signature is not valid")
+	response, err := c.request(ctx, dest, config.TicketProtocolName, []byte(ticket))
+	if err != nil {
+		return nil, err
 	}
 
+	return c.verifySignature(ctx, proposal, proposalBytes, response.Signature)
+}
+
+// signRequest sends a typed request of the sign protocol
+func (c Client) signRequest(ctx context.Context, dest peer.ID, request *model.SignRequest) (*model.SignerResponse, error) {
+	request.NetworkVersion = uint64(c.NetworkVersion)
+	requestBytes, err := cborutil.Dump(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshall request")
+	}
+
+	return c.request(ctx, dest, config.SignProtocolName, requestBytes)
+}
+
+// SignMessage requests the signature of a payload of the message type from the wallet through the sign protocol.
+// The signature is verified against the bytes the wallet signs for the message type.
+func (c Client) SignMessage(ctx context.Context, dest peer.ID, msgType string, wallet address.Address, payload []byte) (*filcrypto.Signature, error) {
+	signature, _, err := c.signTyped(ctx, dest, msgType, wallet, payload)
+	return signature, err
+}
+
+// signTyped requests the signature of the payload through the sign protocol and verifies it.
+// It also returns the signed payload of the message types that have one.
+func (c Client) signTyped(ctx context.Context, dest peer.ID, msgType string, wallet address.Address, payload []byte) (*filcrypto.Signature, []byte, error) {
+	signingBytes, err := message.SigningBytes(msgType, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := c.signRequest(ctx, dest, &model.SignRequest{
+		Type:    msgType,
+		Wallet:  wallet.String(),
+		Payload: payload,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signature := new(filcrypto.Signature)
 	err = signature.UnmarshalBinary(response.Signature)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal response signature")
+		return nil, nil, errors.Wrap(err, "failed to unmarshal response signature")
 	}
-	return signature, nil
+
+	_, err = verify.Signature(ctx, c.resolver(), wallet, signingBytes, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signature, response.Signed, nil
+}
+
+// SignDealProposal requests the signature of the proposal of a Boost deal through the sign protocol.
+// The signer records the deal UUID, so that it signs deal status requests for the deal afterwards.
+func (c Client) SignDealProposal(ctx context.Context, dest peer.ID, proposal filmarket.DealProposal, dealUUID [16]byte) (*filcrypto.Signature, error) {
+	proposalBytes, err := cborutil.Dump(&proposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshall proposal")
+	}
+
+	response, err := c.signRequest(ctx, dest, &model.SignRequest{
+		Type:     model.MessageTypeDealProposal,
+		Payload:  proposalBytes,
+		DealUUID: message.FormatUUID(dealUUID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.verifySignature(ctx, proposal, proposalBytes, response.Signature)
+}
+
+// SignDealStatusRequest requests the client signature over the deal UUID that the Boost deal status protocol requires
+func (c Client) SignDealStatusRequest(ctx context.Context, dest peer.ID, client address.Address, dealUUID [16]byte) (*filcrypto.Signature, error) {
+	return c.SignMessage(ctx, dest, model.MessageTypeDealStatusRequest, client, dealUUID[:])
+}
+
+// SignChainMessage requests the signature of the chain message from its sender wallet.
+// The nonce is not assigned by the signer, so it must be set by the caller.
+func (c Client) SignChainMessage(ctx context.Context, dest peer.ID, msg *chainmsg.Message) (*chainmsg.SignedMessage, error) {
+	msgBytes, err := msg.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	signature, signed, err := c.signTyped(ctx, dest, model.MessageTypeChainMessage, msg.From, msgBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signedMsg := new(chainmsg.SignedMessage)
+	err = signedMsg.UnmarshalCBOR(bytes.NewReader(signed))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal signed message")
+	}
+
+	if !signedMsg.Signature.Equals(signature) {
+		return nil, errors.New("signed message does not carry the returned signature")
+	}
+
+	signedBytes, err := signedMsg.Message.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(signedBytes, msgBytes) {
+		return nil, errors.New("signed message does not match the requested message")
+	}
+
+	return signedMsg, nil
+}
+
+// SignRemoveDataCapProposal requests the signature of the datacap removal proposal from the verifier wallet,
+// and returns the RemoveDataCapRequest to pass to RemoveVerifiedClientDataCap of the verified registry
+func (c Client) SignRemoveDataCapProposal(ctx context.Context, dest peer.ID, verifier address.Address, proposal *verifreg.RemoveDataCapProposal) (*verifreg.RemoveDataCapRequest, error) {
+	proposalBytes, err := cborutil.Dump(proposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal remove datacap proposal")
+	}
+
+	signature, signed, err := c.signTyped(ctx, dest, model.MessageTypeRemoveDataCapProposal, verifier, proposalBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	request := new(verifreg.RemoveDataCapRequest)
+	err = request.UnmarshalCBOR(bytes.NewReader(signed))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal remove datacap request")
+	}
+
+	if !request.VerifierSignature.Equals(signature) {
+		return nil, errors.New("remove datacap request does not carry the returned signature")
+	}
+
+	err = c.checkVerifier(ctx, verifier, request.Verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// checkVerifier checks that the verifier of the returned request is the requested one, by its ID or key address
+func (c Client) checkVerifier(ctx context.Context, requested address.Address, returned address.Address) error {
+	if requested == returned {
+		return nil
+	}
+
+	expected, err := verify.KeyAddress(ctx, c.resolver(), requested)
+	if err != nil {
+		return err
+	}
+
+	actual, err := verify.KeyAddress(ctx, c.resolver(), returned)
+	if err != nil {
+		return err
+	}
+
+	if actual != expected {
+		return errors.Errorf("remove datacap request is for verifier %s, not %s", returned, requested)
+	}
+
+	return nil
 }
 
 // NewClient creates a new client with the default relays
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/config"
+	"github.com/filecoin-project/go-address"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolverNetwork(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":"t01000"}`))
+	}))
+	defer api.Close()
+
+	network := config.Devnet
+	network.RPCEndpoint = api.URL
+	c := Client{Network: network}
+	id, err := c.resolver().LookupID(context.Background(), address.TestAddress)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	expected, err := address.NewIDAddress(1000)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if id != expected {
+		t.Fatalf("unexpected ID address %s", id)
+	}
+}
+
+var (
+	testVerifier, _      = address.NewFromString("f1cbqqzvzx6suldlmxbc33uqjvhkwyjsyvudh3xwi")
+	testOtherVerifier, _ = address.NewFromString("f1ws3n5tuxtyg26lraqkjirz7qon7y7ckju7hhmii")
+)
+
+// testResolver resolves f01000 to testVerifier
+type testResolver struct{}
+
+func (testResolver) LookupID(_ context.Context, addr address.Address) (address.Address, error) {
+	return address.NewIDAddress(1000)
+}
+
+func (testResolver) AccountKey(_ context.Context, addr address.Address) (address.Address, error) {
+	id, err := address.NewIDAddress(1000)
+	if err != nil || addr != id {
+		return address.Undef, errors.Errorf("unknown actor %s", addr)
+	}
+
+	return testVerifier, nil
+}
+
+func TestCheckVerifier(t *testing.T) {
+	c := Client{Resolver: testResolver{}}
+	ctx := context.Background()
+	id, err := address.NewIDAddress(1000)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	for _, requested := range []address.Address{testVerifier, id} {
+		err = c.checkVerifier(ctx, requested, testVerifier)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+	}
+
+	err = c.checkVerifier(ctx, id, testOtherVerifier)
+	if err == nil {
+		t.Fatalf("expected a request for another verifier to be refused")
+	}
+
+	err = c.checkVerifier(ctx, testVerifier, testOtherVerifier)
+	if err == nil {
+		t.Fatalf("expected a request for another verifier to be refused")
+	}
+}
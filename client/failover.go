@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FailoverError is returned when none of the redundant signer peers returned a valid signature
+type FailoverError struct {
+	Errors map[peer.ID]error
+}
+
+func (e *FailoverError) Error() string {
+	dests := make([]peer.ID, 0, len(e.Errors))
+	for dest := range e.Errors {
+		dests = append(dests, dest)
+	}
+	sort.Slice(dests, func(i, j int) bool {
+		return dests[i].String() < dests[j].String()
+	})
+
+	messages := make([]string, 0, len(dests))
+	for _, dest := range dests {
+		messages = append(messages, fmt.Sprintf("%s: %v", dest.String(), e.Errors[dest]))
+	}
+
+	return "all signer peers failed: " + strings.Join(messages, "; ")
+}
+
+// SignProposalWithFailover requests the signature from a set of signer peers that hold the same wallet.
+// The peers are tried in order. If HedgeDelay is set, the next peer is also asked once the previous ones
+// did not answer within the delay, otherwise the next peer is only asked after the previous one failed.
+// The first signature that validates against proposal.Client is returned, regardless of which peer answered.
+// A RequestError, such as PendingApproval or a policy rejection, is returned right away without asking the next peers,
+// so that the proposal is not parked or charged against a budget on every signer.
+// @param dests the peer IDs of the redundant signers, in order of preference
+func (c Client) SignProposalWithFailover(ctx context.Context, dests []peer.ID, proposal filmarket.DealProposal) (*filcrypto.Signature, peer.ID, error) {
+	return hedge(ctx, dests, c.HedgeDelay, func(ctx context.Context, dest peer.ID) (*filcrypto.Signature, error) {
+		return c.SignProposal(ctx, dest, proposal)
+	})
+}
+
+type hedgeResult struct {
+	dest      peer.ID
+	signature *filcrypto.Signature
+	err       error
+}
+
+func hedge(
+	ctx context.Context,
+	dests []peer.ID,
+	delay time.Duration,
+	sign func(context.Context, peer.ID) (*filcrypto.Signature, error),
+) (*filcrypto.Signature, peer.ID, error) {
+	if len(dests) == 0 {
+		return nil, "", errors.New("no signer peers specified")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, len(dests))
+	start := func(dest peer.ID) {
+		go func() {
+			signature, err := sign(ctx, dest)
+			results <- hedgeResult{dest: dest, signature: signature, err: err}
+		}()
+	}
+
+	failures := &FailoverError{Errors: make(map[peer.ID]error)}
+	next := 0
+	pending := 0
+	for {
+		if pending == 0 {
+			if next == len(dests) {
+				return nil, "", failures
+			}
+
+			start(dests[next])
+			next++
+			pending++
+		}
+
+		var hedgeTimer <-chan time.Time
+		if delay > 0 && next < len(dests) {
+			hedgeTimer = time.After(delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, "", errors.Wrap(ctx.Err(), "failed to get signature from signer peers")
+		case <-hedgeTimer:
+			start(dests[next])
+			next++
+			pending++
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.signature, result.dest, nil
+			}
+
+			// The signer answered, so the next ones would only answer the same
+			var requestErr *RequestError
+			if errors.As(result.err, &requestErr) {
+				return nil, result.dest, result.err
+			}
+
+			failures.Errors[result.dest] = result.err
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHedgeFailsOverOnError(t *testing.T) {
+	dests := []peer.ID{"a", "b"}
+	signature, dest, err := hedge(context.Background(), dests, 0, func(ctx context.Context, dest peer.ID) (*filcrypto.Signature, error) {
+		if dest == "a" {
+			return nil, errors.New("down")
+		}
+
+		return &filcrypto.Signature{Type: filcrypto.SigTypeSecp256k1}, nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if dest != "b" || signature == nil {
+		t.Fatalf("expected signature from b, got %v", dest)
+	}
+}
+
+func TestHedgeAsksNextPeerAfterDelay(t *testing.T) {
+	dests := []peer.ID{"slow", "fast"}
+	_, dest, err := hedge(context.Background(), dests, 10*time.Millisecond, func(ctx context.Context, dest peer.ID) (*filcrypto.Signature, error) {
+		if dest == "slow" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		return &filcrypto.Signature{Type: filcrypto.SigTypeSecp256k1}, nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if dest != "fast" {
+		t.Fatalf("expected signature from fast, got %v", dest)
+	}
+}
+
+func TestHedgeAllFailed(t *testing.T) {
+	dests := []peer.ID{"a", "b"}
+	_, _, err := hedge(context.Background(), dests, 0, func(ctx context.Context, dest peer.ID) (*filcrypto.Signature, error) {
+		return nil, errors.New("down")
+	})
+
+	var failoverErr *FailoverError
+	if !errors.As(err, &failoverErr) {
+		t.Fatalf("expected FailoverError, got %v", err)
+	}
+
+	if len(failoverErr.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(failoverErr.Errors))
+	}
+}
+
+func TestHedgeReturnsRequestError(t *testing.T) {
+	dests := []peer.ID{"a", "b"}
+	asked := 0
+	_, dest, err := hedge(context.Background(), dests, 0, func(ctx context.Context, dest peer.ID) (*filcrypto.Signature, error) {
+		asked++
+		return nil, &RequestError{StatusCode: model.PendingApproval, Ticket: "ticket"}
+	})
+
+	var requestErr *RequestError
+	if !errors.As(err, &requestErr) || requestErr.StatusCode != model.PendingApproval {
+		t.Fatalf("expected PendingApproval, got %v", err)
+	}
+
+	if dest != "a" || asked != 1 {
+		t.Fatalf("expected only a to be asked, got %v after %d requests", dest, asked)
+	}
+}
+
+func TestFailoverErrorOrder(t *testing.T) {
+	err := &FailoverError{Errors: map[peer.ID]error{
+		"c": errors.New("down"),
+		"a": errors.New("down"),
+		"b": errors.New("down"),
+	}}
+	expected := peer.ID("a").String() + ": down; " + peer.ID("b").String() + ": down; " + peer.ID("c").String() + ": down"
+	for i := 0; i < 10; i++ {
+		if !strings.HasSuffix(err.Error(), expected) {
+			t.Fatalf("unexpected error order: %v", err)
+		}
+	}
+}
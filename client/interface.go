@@ -10,3 +10,7 @@ import (
 type Signer interface {
 	SignProposal(ctx context.Context, dest peer.ID, proposal filmarket.DealProposal) (*crypto.Signature, error)
 }
+
+type FailoverSigner interface {
+	SignProposalWithFailover(ctx context.Context, dests []peer.ID, proposal filmarket.DealProposal) (*crypto.Signature, peer.ID, error)
+}
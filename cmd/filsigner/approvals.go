@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/admin"
+	"github.com/data-preservation-programs/filsigner-relayed/approval"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"os"
+	"text/tabwriter"
+)
+
+var adminSocketFlag = &cli.StringFlag{
+	Name:     "admin-socket",
+	Usage:    "The path of the admin unix socket of the running filsigner server",
+	EnvVars:  []string{"ADMIN_SOCKET"},
+	Required: true,
+}
+
+var noteFlag = &cli.StringFlag{
+	Name:  "note",
+	Usage: "A note recorded with the decision in the audit trail",
+}
+
+func printJSON(value any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return errors.Wrap(encoder.Encode(value), "cannot encode output")
+}
+
+func approvalsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "approvals",
+		Usage: "Manage the proposals waiting for manual approval on a running filsigner server",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the tickets in the approval queue",
+				Flags: []cli.Flag{
+					adminSocketFlag,
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "Also list approved and rejected tickets",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the tickets as JSON",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					tickets, err := admin.NewClient(c.String("admin-socket")).ListTickets(c.Context)
+					if err != nil {
+						return errors.Wrap(err, "cannot list tickets")
+					}
+
+					if c.Bool("json") {
+						return printJSON(tickets)
+					}
+
+					writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(writer, "TICKET\tSTATUS\tCLIENT\tPROVIDER\tPIECE SIZE\tCREATED\tREASONS")
+					for _, ticket := range tickets {
+						if !c.Bool("all") && ticket.Status != approval.Pending {
+							continue
+						}
+
+						fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\t%v\n",
+							ticket.ID, ticket.Status, ticket.Client, ticket.Provider, ticket.PieceSize,
+							ticket.Created.Format("2006-01-02 15:04:05"), ticket.Reasons)
+					}
+
+					return errors.Wrap(writer.Flush(), "cannot write output")
+				},
+			},
+			{
+				Name:      "approve",
+				Usage:     "Approve and sign a pending proposal",
+				ArgsUsage: "<ticket>",
+				Flags:     []cli.Flag{adminSocketFlag, noteFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("expected exactly one ticket")
+					}
+
+					ticket, err := admin.NewClient(c.String("admin-socket")).ApproveTicket(c.Context, c.Args().First(), c.String("note"))
+					if err != nil {
+						return errors.Wrap(err, "cannot approve ticket")
+					}
+
+					return printJSON(ticket)
+				},
+			},
+			{
+				Name:      "reject",
+				Usage:     "Reject a pending proposal",
+				ArgsUsage: "<ticket>",
+				Flags:     []cli.Flag{adminSocketFlag, noteFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("expected exactly one ticket")
+					}
+
+					ticket, err := admin.NewClient(c.String("admin-socket")).RejectTicket(c.Context, c.Args().First(), c.String("note"))
+					if err != nil {
+						return errors.Wrap(err, "cannot reject ticket")
+					}
+
+					return printJSON(ticket)
+				},
+			},
+		},
+	}
+}
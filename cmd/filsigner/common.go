@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/base64"
+	client2 "github.com/data-preservation-programs/filsigner-relayed/client"
+	"github.com/data-preservation-programs/filsigner-relayed/config"
+	"github.com/data-preservation-programs/filsigner-relayed/secret"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"time"
+)
+
+var networkFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "network",
+		Usage:   "The Filecoin network to run on: mainnet, calibnet or devnet",
+		Value:   config.Mainnet.Name,
+		EnvVars: []string{"NETWORK"},
+	},
+	&cli.Int64Flag{
+		Name:    "genesis-timestamp",
+		Usage:   "The unix time of the genesis block, for networks such as devnets whose genesis is not known",
+		EnvVars: []string{"GENESIS_TIMESTAMP"},
+	},
+}
+
+// currentNetwork returns the network of the flags
+func currentNetwork(c *cli.Context) (config.Network, error) {
+	network, err := config.GetNetwork(c.String("network"))
+	if err != nil {
+		return config.Network{}, errors.Wrap(err, "cannot select network")
+	}
+
+	if c.Int64("genesis-timestamp") != 0 {
+		network.Genesis = time.Unix(c.Int64("genesis-timestamp"), 0)
+	}
+
+	return network, nil
+}
+
+// newClient creates a client for the signers of the network of the flags
+func newClient(c *cli.Context, identityKey crypto.PrivKey, relays []peer.AddrInfo) (*client2.Client, error) {
+	network, err := currentNetwork(c)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := client2.NewClient(identityKey, relays)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Network = network
+	client.Resolver, err = newResolver(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// decodePrivateKey decodes a base64 encoded libp2p private key, as printed by generate-peer
+func decodePrivateKey(value string) (crypto.PrivKey, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode private key")
+	}
+
+	key, err := crypto.UnmarshalPrivateKey(keyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal private key")
+	}
+
+	return key, nil
+}
+
+// secretValue returns the value of the secret flag, or reads it from the file given with the "<name>-file" flag
+func secretValue(c *cli.Context, name string) (string, error) {
+	if c.String(name) != "" || c.String(name+"-file") == "" {
+		return c.String(name), nil
+	}
+
+	value, err := secret.ReadString(c.String(name + "-file"))
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot read %s", name)
+	}
+
+	return value, nil
+}
+
+// parseRelays decodes the relay infos, falling back to the default relay servers of the network
+func parseRelays(c *cli.Context, relayInfos []string) ([]peer.AddrInfo, error) {
+	if len(relayInfos) == 0 {
+		network, err := currentNetwork(c)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(network.Relays) == 0 {
+			return nil, errors.Errorf("%s has no default relays, set them with --relay-info", network.Name)
+		}
+
+		relays, err := network.RelayInfo()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode default relays")
+		}
+
+		return relays, nil
+	}
+
+	relays := make([]peer.AddrInfo, len(relayInfos))
+	for i, relayInfo := range relayInfos {
+		relay, err := peer.AddrInfoFromString(relayInfo)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode relay info %s", relayInfo)
+		}
+
+		relays[i] = *relay
+	}
+
+	return relays, nil
+}
+
+func parsePeers(values []string) ([]peer.ID, error) {
+	peers := make([]peer.ID, len(values))
+	for i, value := range values {
+		var err error
+		peers[i], err = peer.Decode(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode peer ID %s", value)
+		}
+	}
+
+	return peers, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/data-preservation-programs/filsigner-relayed/admin"
+	"github.com/data-preservation-programs/filsigner-relayed/cosign"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"os"
+	"time"
+)
+
+func cosignCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "cosign",
+		Usage: "Create and submit detached approvals for wallets under the two-person rule",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Sign an approval with an operator key. This can run offline",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "key",
+						Usage:   "The base64 encoded libp2p private key of the approver, as printed by generate-peer",
+						EnvVars: []string{"COSIGN_KEY"},
+					},
+					&cli.StringFlag{
+						Name:    "key-file",
+						Usage:   "The file to read the base64 encoded private key of the approver from",
+						EnvVars: []string{"COSIGN_KEY_FILE"},
+					},
+					&cli.StringFlag{
+						Name:  "proposal-cid",
+						Usage: "Approve exactly the proposal with this CID",
+					},
+					&cli.StringFlag{
+						Name:  "piece-cid",
+						Usage: "Approve proposals for this piece CID, when no proposal CID is given",
+					},
+					&cli.StringSliceFlag{
+						Name:  "provider",
+						Usage: "Restrict the piece approval to these providers",
+					},
+					&cli.StringFlag{
+						Name:  "client",
+						Usage: "Restrict the piece approval to this client address",
+					},
+					&cli.DurationFlag{
+						Name:  "expires-in",
+						Usage: "How long the approval stays valid. Required for piece approvals",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Usage: "Write the signed approval to this file instead of stdout",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					value, err := secretValue(c, "key")
+					if err != nil {
+						return err
+					}
+
+					if value == "" {
+						return errors.New("an approver key or key file is required")
+					}
+
+					key, err := decodePrivateKey(value)
+					if err != nil {
+						return errors.Wrap(err, "cannot decode approver key")
+					}
+
+					approval := cosign.Approval{
+						ProposalCID: c.String("proposal-cid"),
+						PieceCID:    c.String("piece-cid"),
+						Providers:   c.StringSlice("provider"),
+						Client:      c.String("client"),
+					}
+					if c.Duration("expires-in") > 0 {
+						approval.Expiry = time.Now().Add(c.Duration("expires-in")).UTC()
+					}
+
+					signed, err := cosign.Sign(key, approval)
+					if err != nil {
+						return errors.Wrap(err, "cannot sign approval")
+					}
+
+					if c.String("out") == "" {
+						return printJSON(signed)
+					}
+
+					content, err := json.MarshalIndent(signed, "", "  ")
+					if err != nil {
+						return errors.Wrap(err, "cannot encode approval")
+					}
+
+					return errors.Wrap(os.WriteFile(c.String("out"), content, 0o600), "cannot write approval")
+				},
+			},
+			{
+				Name:      "submit",
+				Usage:     "Submit a signed approval to a running filsigner server",
+				ArgsUsage: "<approval file>",
+				Flags:     []cli.Flag{adminSocketFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("expected exactly one approval file")
+					}
+
+					content, err := os.ReadFile(c.Args().First())
+					if err != nil {
+						return errors.Wrap(err, "cannot read approval")
+					}
+
+					signed := cosign.SignedApproval{}
+					err = json.Unmarshal(content, &signed)
+					if err != nil {
+						return errors.Wrap(err, "cannot decode approval")
+					}
+
+					err = admin.NewClient(c.String("admin-socket")).SubmitApproval(c.Context, signed)
+					return errors.Wrap(err, "cannot submit approval")
+				},
+			},
+		},
+	}
+}
@@ -1,23 +1,27 @@
 package main
 
 import (
-	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/admin"
 	client2 "github.com/data-preservation-programs/filsigner-relayed/client"
-	"github.com/data-preservation-programs/filsigner-relayed/config"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
 	"github.com/data-preservation-programs/filsigner-relayed/server"
+	"github.com/data-preservation-programs/filsigner-relayed/walletapi"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
 	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
-	"github.com/libp2p/go-libp2p/core/crypto"
-	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/urfave/cli/v2"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
@@ -29,37 +33,43 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 
 func main() {
 	log := logging.Logger("server")
-	address.CurrentNetwork = address.Mainnet
 	allowedRequestersArg := new(cli.StringSlice)
 	signKeysArg := new(cli.StringSlice)
-	identityKeyArg := new(string)
 	relayInfos := new(cli.StringSlice)
 
-	destination := new(string)
+	destinations := new(cli.StringSlice)
+	hedgeDelay := new(time.Duration)
 	client := new(string)
 
 	app := &cli.App{
-		Name: "filsigner",
+		Name:  "filsigner",
+		Flags: networkFlags,
+		Before: func(c *cli.Context) error {
+			network, err := currentNetwork(c)
+			if err != nil {
+				return err
+			}
+
+			address.CurrentNetwork = network.Address
+			return nil
+		},
 		Commands: []*cli.Command{
 			{
 				Name:  "test",
 				Usage: "Request a signature from the filsigner server for a test proposal",
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:        "identity-key",
-						Aliases:     []string{"k"},
-						Usage:       "The base64 encoded private key of the peer to use as the identity",
-						Destination: identityKeyArg,
-						EnvVars:     []string{"IDENTITY_KEY"},
-						Required:    true,
-					},
-					&cli.StringFlag{
+				Flags: append([]cli.Flag{
+					&cli.StringSliceFlag{
 						Name:        "destination",
 						Aliases:     []string{"d"},
-						Usage:       "The peer ID to send the deal proposal to",
-						Destination: destination,
+						Usage:       "The peer ID to send the deal proposal to. Specify multiple redundant signers holding the same wallet to fail over between them",
+						Destination: destinations,
 						Required:    true,
 					},
+					&cli.DurationFlag{
+						Name:        "hedge-delay",
+						Usage:       "How long to wait for a signer before also asking the next destination. Zero only fails over on error",
+						Destination: hedgeDelay,
+					},
 					&cli.StringFlag{
 						Name:        "client",
 						Aliases:     []string{"c"},
@@ -73,48 +83,45 @@ func main() {
 						Destination: relayInfos,
 						EnvVars:     []string{"RELAY_INFOS"},
 					},
-				},
+				}, identityFlags...),
 				Action: func(c *cli.Context) error {
-					identityKeyBytes, err := base64.StdEncoding.DecodeString(*identityKeyArg)
+					identityKey, err := loadIdentity(c)
 					if err != nil {
-						return errors.Wrap(err, "cannot decode identity key")
+						return err
 					}
 
-					identityKey, err := crypto.UnmarshalPrivateKey(identityKeyBytes)
+					destinationPeers, err := parsePeers(destinations.Value())
 					if err != nil {
-						return errors.Wrap(err, "cannot unmarshal identity private key")
+						return errors.Wrap(err, "cannot decode destination")
 					}
 
-					destinationPeer, err := peer.Decode(*destination)
+					clientAddr, err := address.NewFromString(*client)
 					if err != nil {
-						return errors.Wrap(err, "cannot decode destination peer ID")
+						return errors.Wrap(err, "cannot decode client address")
 					}
 
-					clientAddr, err := address.NewFromString(*client)
+					relays, err := parseRelays(c, relayInfos.Value())
 					if err != nil {
-						return errors.Wrap(err, "cannot decode client address")
+						return err
 					}
 
-					var relays []peer.AddrInfo
-					if len(relayInfos.Value()) == 0 {
-						relays = config.GetDefaultRelayInfo()
-					} else {
-						relays = make([]peer.AddrInfo, len(relayInfos.Value()))
-						for i, relayInfo := range relayInfos.Value() {
-							relay, err := peer.AddrInfoFromString(relayInfo)
-							if err != nil {
-								return errors.Wrapf(err, "cannot decode relay info %s", relayInfo)
-							}
+					client, err := newClient(c, identityKey, relays)
+					if err != nil {
+						return errors.Wrap(err, "cannot create client")
+					}
+					client.HedgeDelay = *hedgeDelay
 
-							relays[i] = *relay
-						}
+					network, err := currentNetwork(c)
+					if err != nil {
+						return err
 					}
 
-					client, err := client2.NewClient(identityKey, relays)
+					current, err := network.Epoch(time.Now())
 					if err != nil {
-						return errors.Wrap(err, "cannot create client")
+						return errors.Wrap(err, "cannot compute the current epoch")
 					}
 
+					startEpoch := current + builtin.EpochsInDay
 					proposal := filmarket.DealProposal{
 						PieceCID:             cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
 						PieceSize:            256,
@@ -122,18 +129,25 @@ func main() {
 						Client:               clientAddr,
 						Provider:             address.TestAddress,
 						Label:                filmarket.EmptyDealLabel,
-						StartEpoch:           0,
-						EndEpoch:             0,
+						StartEpoch:           startEpoch,
+						EndEpoch:             startEpoch + filmarket.DealMinDuration,
 						StoragePricePerEpoch: abi.TokenAmount{},
 						ProviderCollateral:   abi.TokenAmount{},
 						ClientCollateral:     abi.TokenAmount{},
 					}
 
-					signature, err := client.SignProposal(c.Context, destinationPeer, proposal)
+					signature, signer, err := client.SignProposalWithFailover(c.Context, destinationPeers, proposal)
+					var requestErr *client2.RequestError
+					if errors.As(err, &requestErr) && requestErr.StatusCode == model.PendingApproval {
+						log.Infof("Proposal is pending manual approval with ticket %s, run the test again to poll it", requestErr.Ticket)
+						return nil
+					}
 					if err != nil {
 						return errors.Wrap(err, "cannot sign proposal")
 					}
 
+					log.Infof("Signed by: %s", signer.String())
+
 					signatureBytes, err := signature.MarshalBinary()
 					if err != nil {
 						return errors.Wrap(err, "cannot marshal signature")
@@ -146,7 +160,7 @@ func main() {
 			{
 				Name:  "run",
 				Usage: "Run the filsigner server to sign deal proposals",
-				Flags: []cli.Flag{
+				Flags: append([]cli.Flag{
 					&cli.StringSliceFlag{
 						Name:        "allowed-requester",
 						Aliases:     []string{"r"},
@@ -155,21 +169,12 @@ func main() {
 						EnvVars:     []string{"ALLOWED_REQUESTERS"},
 						Required:    true,
 					},
-					&cli.StringFlag{
-						Name:        "identity-key",
-						Aliases:     []string{"k"},
-						Usage:       "The base64 encoded private key of the peer to use as the identity",
-						Destination: identityKeyArg,
-						EnvVars:     []string{"IDENTITY_KEY"},
-						Required:    true,
-					},
 					&cli.StringSliceFlag{
 						Name:        "sign-key",
 						Aliases:     []string{"s"},
-						Usage:       "The private key of the address to sign with",
+						Usage:       "The private key of the address to sign with, as exported by 'lotus wallet export'",
 						Destination: signKeysArg,
 						EnvVars:     []string{"SIGN_KEYS"},
-						Required:    true,
 					},
 					&cli.StringSliceFlag{
 						Name:        "relay-info",
@@ -177,49 +182,65 @@ func main() {
 						Destination: relayInfos,
 						EnvVars:     []string{"RELAY_INFOS"},
 					},
-				},
+				}, append(identityFlags, serverOptionFlags...)...),
 				Action: func(c *cli.Context) error {
-					identityKeyBytes, err := base64.StdEncoding.DecodeString(*identityKeyArg)
+					identityKey, err := loadIdentity(c)
 					if err != nil {
-						return errors.Wrap(err, "cannot decode identity key")
+						return err
 					}
 
-					identityKey, err := crypto.UnmarshalPrivateKey(identityKeyBytes)
+					allowedRequesters, err := parsePeers(allowedRequestersArg.Value())
 					if err != nil {
-						return errors.Wrap(err, "cannot unmarshal identity private key")
+						return errors.Wrap(err, "cannot decode allowed requester")
 					}
 
-					allowedRequesters := make([]peer.ID, len(allowedRequestersArg.Value()))
-					for i, allowedRequester := range allowedRequestersArg.Value() {
-						allowedRequesters[i], err = peer.Decode(allowedRequester)
-						if err != nil {
-							return errors.Wrapf(err, "cannot decode allowed requester %s", allowedRequester)
-						}
+					relays, err := parseRelays(c, relayInfos.Value())
+					if err != nil {
+						return err
 					}
 
-					var relays []peer.AddrInfo
-					if len(relayInfos.Value()) == 0 {
-						relays = config.GetDefaultRelayInfo()
-					} else {
-						relays = make([]peer.AddrInfo, len(relayInfos.Value()))
-						for i, relayInfo := range relayInfos.Value() {
-							relay, err := peer.AddrInfoFromString(relayInfo)
-							if err != nil {
-								return errors.Wrapf(err, "cannot decode relay info %s", relayInfo)
-							}
+					if !hasWalletSources(c) {
+						return errors.New("at least one sign key, keystore or wallet API is required")
+					}
 
-							relays[i] = *relay
-						}
+					options, closer, err := serverOptions(c)
+					defer closer()
+					if err != nil {
+						return err
 					}
 
-					server, err := server.NewServer(identityKey, allowedRequesters, signKeysArg.Value(), relays)
+					server, err := server.NewServer(identityKey, allowedRequesters, signKeysArg.Value(), relays, options...)
 					if err != nil {
 						return errors.Wrap(err, "cannot create new server")
 					}
 
+					if c.String("wallet-api-listen") != "" {
+						tokens, err := walletAPITokens(c)
+						if err != nil {
+							return err
+						}
+
+						go func() {
+							err := walletapi.Serve(c.Context, c.String("wallet-api-listen"), walletapi.NewMux(server, tokens))
+							if err != nil {
+								log.Errorw("wallet api stopped", "error", err)
+							}
+						}()
+					}
+
+					if c.String("admin-socket") != "" {
+						go func() {
+							err := admin.Serve(c.Context, c.String("admin-socket"), admin.NewMux(server))
+							if err != nil {
+								log.Errorw("admin socket stopped", "error", err)
+							}
+						}()
+					}
+
 					go func() {
 						// Register the healthHandler function for the /health route
 						http.HandleFunc("/healthz", healthHandler)
+						http.Handle("/metrics", promhttp.Handler())
 
 						// Start the HTTP server on port 8088
 						fmt.Println("Listening on :8088...")
@@ -229,6 +250,18 @@ func main() {
 						}
 					}()
 
+					// Read the key files, secrets and piece manifest again on SIGHUP
+					reload := make(chan os.Signal, 1)
+					signal.Notify(reload, syscall.SIGHUP)
+					go func() {
+						for range reload {
+							err := server.Reload(c.Context)
+							if err != nil {
+								log.Errorw("cannot reload", "error", err)
+							}
+						}
+					}()
+
 					err = server.Start(c.Context)
 					if err != nil {
 						return errors.Wrap(err, "cannot start server")
@@ -237,25 +270,16 @@ func main() {
 					return nil
 				},
 			},
-			{
-				Name:  "generate-peer",
-				Usage: "generate a new peer id with private key",
-				Action: func(c *cli.Context) error {
-					privateStr, publicStr, peerStr, err := GenerateNewPeer()
-					if err != nil {
-						return errors.Wrap(err, "cannot generate new peer")
-					}
-
-					//nolint:forbidigo
-					{
-						fmt.Println("New peer generated using ed25519, keys are encoded in base64")
-						fmt.Println("peer id:     ", peerStr.String())
-						fmt.Println("public key:  ", publicStr)
-						fmt.Println("private key: ", privateStr)
-					}
-					return nil
-				},
-			},
+			approvalsCommand(),
+			cosignCommand(),
+			pingCommand(),
+			inspectCommand(),
+			manifestCommand(),
+			signCommand(),
+			signMessageCommand(),
+			verifyCommand(),
+			walletCommand(),
+			generatePeerCommand(),
 		},
 	}
 
@@ -264,29 +288,3 @@ func main() {
 		log.Fatalf("Failed to run filsigner: %v", err)
 	}
 }
-func GenerateNewPeer() (string, string, peer.ID, error) {
-	private, public, err := crypto.GenerateEd25519Key(rand.Reader)
-	if err != nil {
-		return "", "", "", errors.Wrap(err, "cannot generate new peer")
-	}
-
-	peerID, err := peer.IDFromPublicKey(public)
-	if err != nil {
-		return "", "", "", errors.Wrap(err, "cannot generate peer id")
-	}
-
-	privateBytes, err := crypto.MarshalPrivateKey(private)
-	if err != nil {
-		return "", "", "", errors.Wrap(err, "cannot marshal private key")
-	}
-
-	privateStr := base64.StdEncoding.EncodeToString(privateBytes)
-
-	publicBytes, err := crypto.MarshalPublicKey(public)
-	if err != nil {
-		return "", "", "", errors.Wrap(err, "cannot marshal public key")
-	}
-
-	publicStr := base64.StdEncoding.EncodeToString(publicBytes)
-	return privateStr, publicStr, peerID, nil
-}
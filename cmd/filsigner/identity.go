@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/secret"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"os"
+	"path/filepath"
+)
+
+var identityFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "identity-key",
+		Aliases: []string{"k"},
+		Usage:   "The base64 encoded private key of the peer to use as the identity",
+		EnvVars: []string{"IDENTITY_KEY"},
+	},
+	&cli.StringFlag{
+		Name:    "identity-key-file",
+		Usage:   "The file holding the private key of the peer, as written by 'generate-peer --out' or 'ipfs key export', in base64, PEM or a kubo config",
+		EnvVars: []string{"IDENTITY_KEY_FILE"},
+	},
+	&cli.BoolFlag{
+		Name:    "generate-identity",
+		Usage:   "Generate a new ed25519 identity in --identity-key-file if the file does not exist",
+		EnvVars: []string{"GENERATE_IDENTITY"},
+	},
+}
+
+// keyTypes are the libp2p key types generate-peer can create
+var keyTypes = map[string]int{
+	"ed25519":   crypto.Ed25519,
+	"secp256k1": crypto.Secp256k1,
+	"ecdsa":     crypto.ECDSA,
+}
+
+// peerInfo is the identity printed by generate-peer, with keys encoded in base64
+type peerInfo struct {
+	PeerID     string `json:"peerId"`
+	Type       string `json:"type"`
+	PublicKey  string `json:"publicKey"`
+	PrivateKey string `json:"privateKey,omitempty"`
+}
+
+// loadIdentity returns the identity key from the flags, generating the identity key file if requested
+func loadIdentity(c *cli.Context) (crypto.PrivKey, error) {
+	if c.String("identity-key") != "" {
+		key, err := decodePrivateKey(c.String("identity-key"))
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode identity key")
+		}
+		return key, nil
+	}
+
+	path := c.String("identity-key-file")
+	if path == "" {
+		return nil, errors.New("an identity key or identity key file is required")
+	}
+
+	content, err := secret.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && c.Bool("generate-identity") {
+		key, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot generate identity key")
+		}
+
+		err = writeIdentityFile(path, key)
+		if err != nil {
+			return nil, err
+		}
+
+		peerID, err := peer.IDFromPrivateKey(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot derive peer ID")
+		}
+
+		logging.Logger("identity").Infow("generated new identity", "peer", peerID.String(), "file", path)
+		return key, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read identity key file")
+	}
+
+	key, err := parseIdentityFile(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot decode identity key file %s", path)
+	}
+
+	return key, nil
+}
+
+// parseIdentityFile decodes a libp2p private key from a key file, which is either the protobuf encoded key
+// ('ipfs key export'), a PKCS8 PEM block ('ipfs key export --format=pem-pkcs8-cleartext'), a base64 encoded key
+// (generate-peer), the JSON output of generate-peer, or a kubo config with an Identity section
+func parseIdentityFile(content []byte) (crypto.PrivKey, error) {
+	trimmed := bytes.TrimSpace(content)
+	if block, _ := pem.Decode(trimmed); block != nil {
+		stdKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse PEM private key")
+		}
+
+		// libp2p only converts ed25519 keys given by pointer
+		if edKey, ok := stdKey.(ed25519.PrivateKey); ok {
+			stdKey = &edKey
+		}
+
+		key, _, err := crypto.KeyPairFromStdKey(stdKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot convert PEM private key")
+		}
+		return key, nil
+	}
+
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var config struct {
+			PrivateKey string `json:"privateKey"`
+			Identity   struct {
+				PrivKey string
+			}
+		}
+		err := json.Unmarshal(trimmed, &config)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode JSON key file")
+		}
+
+		if config.Identity.PrivKey != "" {
+			return decodePrivateKey(config.Identity.PrivKey)
+		}
+		return decodePrivateKey(config.PrivateKey)
+	}
+
+	if key, err := decodePrivateKey(string(trimmed)); err == nil {
+		return key, nil
+	}
+
+	key, err := crypto.UnmarshalPrivateKey(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "unknown key file format")
+	}
+
+	return key, nil
+}
+
+// writeIdentityFile writes the key in the protobuf encoding used by 'ipfs key export', readable only by the owner
+func writeIdentityFile(path string, key crypto.PrivKey) error {
+	keyBytes, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal private key")
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return errors.Wrap(err, "cannot create identity key directory")
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "cannot create identity key file")
+	}
+	defer file.Close()
+
+	_, err = file.Write(keyBytes)
+	if err != nil {
+		return errors.Wrap(err, "cannot write identity key file")
+	}
+
+	return errors.Wrap(file.Sync(), "cannot write identity key file")
+}
+
+// newPeerInfo describes the key, including the private key only if withPrivate is set
+func newPeerInfo(keyType string, key crypto.PrivKey, withPrivate bool) (*peerInfo, error) {
+	peerID, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot generate peer id")
+	}
+
+	publicBytes, err := crypto.MarshalPublicKey(key.GetPublic())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot marshal public key")
+	}
+
+	info := &peerInfo{
+		PeerID:    peerID.String(),
+		Type:      keyType,
+		PublicKey: base64.StdEncoding.EncodeToString(publicBytes),
+	}
+
+	if withPrivate {
+		privateBytes, err := crypto.MarshalPrivateKey(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot marshal private key")
+		}
+		info.PrivateKey = base64.StdEncoding.EncodeToString(privateBytes)
+	}
+
+	return info, nil
+}
+
+func generatePeerCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "generate-peer",
+		Usage: "generate a new peer id with private key",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "The key type: ed25519, secp256k1 or ecdsa",
+				Value: "ed25519",
+			},
+			&cli.StringFlag{
+				Name:  "out",
+				Usage: "Write the private key to this file, readable only by the owner, instead of printing it",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the peer as JSON",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			keyType, ok := keyTypes[c.String("type")]
+			if !ok {
+				return errors.Errorf("unsupported key type %s", c.String("type"))
+			}
+
+			key, _, err := crypto.GenerateKeyPair(keyType, -1)
+			if err != nil {
+				return errors.Wrap(err, "cannot generate new peer")
+			}
+
+			if c.String("out") != "" {
+				err = writeIdentityFile(c.String("out"), key)
+				if err != nil {
+					return err
+				}
+			}
+
+			info, err := newPeerInfo(c.String("type"), key, c.String("out") == "")
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("json") {
+				return printJSON(info)
+			}
+
+			//nolint:forbidigo
+			{
+				fmt.Printf("New peer generated using %s, keys are encoded in base64\n", info.Type)
+				fmt.Println("peer id:     ", info.PeerID)
+				fmt.Println("public key:  ", info.PublicKey)
+				if info.PrivateKey != "" {
+					fmt.Println("private key: ", info.PrivateKey)
+				} else {
+					fmt.Println("private key written to", c.String("out"))
+				}
+			}
+			return nil
+		},
+	}
+}
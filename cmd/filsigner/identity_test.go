@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIdentityFile(t *testing.T) {
+	for name, keyType := range keyTypes {
+		key, _, err := crypto.GenerateKeyPair(keyType, -1)
+		if err != nil {
+			t.Fatalf("%s: err is not null: %v", name, err)
+		}
+
+		path := filepath.Join(t.TempDir(), "keys", "identity.key")
+		err = writeIdentityFile(path, key)
+		if err != nil {
+			t.Fatalf("%s: err is not null: %v", name, err)
+		}
+
+		stat, err := os.Stat(path)
+		if err != nil || stat.Mode().Perm() != 0o600 {
+			t.Fatalf("%s: unexpected identity file mode: %v %v", name, stat, err)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s: err is not null: %v", name, err)
+		}
+
+		info, err := newPeerInfo(name, key, true)
+		if err != nil {
+			t.Fatalf("%s: err is not null: %v", name, err)
+		}
+
+		kuboConfig, err := json.Marshal(map[string]any{"Identity": map[string]string{"PeerID": info.PeerID, "PrivKey": info.PrivateKey}})
+		if err != nil {
+			t.Fatalf("%s: err is not null: %v", name, err)
+		}
+
+		generatePeerOutput, err := json.Marshal(info)
+		if err != nil {
+			t.Fatalf("%s: err is not null: %v", name, err)
+		}
+
+		for format, content := range map[string][]byte{
+			"protobuf":      content,
+			"base64":        []byte(info.PrivateKey + "\n"),
+			"kubo config":   kuboConfig,
+			"generate-peer": generatePeerOutput,
+		} {
+			parsed, err := parseIdentityFile(content)
+			if err != nil {
+				t.Fatalf("%s %s: err is not null: %v", name, format, err)
+			}
+
+			if !parsed.Equals(key) {
+				t.Fatalf("%s %s: parsed key does not match", name, format)
+			}
+		}
+	}
+}
+
+func TestIdentityFilePEM(t *testing.T) {
+	key, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	stdKey, err := crypto.PrivKeyToStdKey(key)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(*stdKey.(*ed25519.PrivateKey))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	parsed, err := parseIdentityFile(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if !parsed.Equals(key) {
+		t.Fatalf("parsed key does not match")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/data-preservation-programs/filsigner-relayed/inspect"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"io"
+	"strings"
+)
+
+const (
+	encodingAuto   = "auto"
+	encodingHex    = "hex"
+	encodingBase64 = "base64"
+	encodingRaw    = "raw"
+)
+
+// decodeBytes turns the input into raw CBOR bytes, auto detecting hex and base64 text
+func decodeBytes(input []byte, encoding string) ([]byte, error) {
+	text := string(bytes.TrimSpace(input))
+	switch encoding {
+	case encodingRaw:
+		return input, nil
+	case encodingHex:
+		data, err := hex.DecodeString(strings.TrimPrefix(text, "0x"))
+		return data, errors.Wrap(err, "cannot decode hex")
+	case encodingBase64:
+		data, err := base64.StdEncoding.DecodeString(text)
+		return data, errors.Wrap(err, "cannot decode base64")
+	case encodingAuto:
+		if data, err := hex.DecodeString(strings.TrimPrefix(text, "0x")); err == nil {
+			return data, nil
+		}
+		if data, err := base64.StdEncoding.DecodeString(text); err == nil {
+			return data, nil
+		}
+		return input, nil
+	default:
+		return nil, errors.Errorf("unsupported encoding %s", encoding)
+	}
+}
+
+func inspectCommand() *cli.Command {
+	typeNames := make([]string, 0, len(inspect.Types))
+	for _, typ := range inspect.Types {
+		typeNames = append(typeNames, typ.Name)
+	}
+
+	return &cli.Command{
+		Name:      "inspect",
+		Usage:     "Decode proposals, requests and responses captured from the wire, and show why a proposal does not re-marshal",
+		ArgsUsage: "[file, or - for stdin]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "data",
+				Usage: "The bytes to decode, instead of reading them from a file or stdin",
+			},
+			&cli.StringFlag{
+				Name:  "encoding",
+				Usage: "The encoding of the input: hex, base64, raw or auto",
+				Value: encodingAuto,
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "The type to decode the input as: " + strings.Join(typeNames, ", ") + ". If not set, all types are tried",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			input := []byte(c.String("data"))
+			if !c.IsSet("data") {
+				inputs, err := openInputs(c.Args().Slice())
+				if err != nil {
+					return err
+				}
+
+				if len(inputs) != 1 {
+					for _, input := range inputs {
+						input.Close()
+					}
+					return errors.New("expected a single input")
+				}
+
+				input, err = io.ReadAll(inputs[0])
+				inputs[0].Close()
+				if err != nil {
+					return errors.Wrap(err, "cannot read input")
+				}
+			}
+
+			data, err := decodeBytes(input, c.String("encoding"))
+			if err != nil {
+				return err
+			}
+
+			result, err := inspect.Decode(data, c.String("type"))
+			if err != nil {
+				return errors.Wrap(err, "cannot decode input")
+			}
+
+			return printJSON(result)
+		},
+	}
+}
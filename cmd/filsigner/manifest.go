@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/data-preservation-programs/filsigner-relayed/manifest"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"os"
+)
+
+func manifestCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "manifest",
+		Usage: "Sign the manifest of the pieces the signer signs proposals for",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "sign",
+				Usage:     "Sign a CSV or JSON piece manifest with an operator key. This can run offline",
+				ArgsUsage: "<manifest file>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "key",
+						Usage:   "The base64 encoded libp2p private key of the operator, as printed by generate-peer",
+						EnvVars: []string{"MANIFEST_KEY"},
+					},
+					&cli.StringFlag{
+						Name:    "key-file",
+						Usage:   "The file to read the base64 encoded private key of the operator from",
+						EnvVars: []string{"MANIFEST_KEY_FILE"},
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Usage: "Write the signed manifest to this file instead of stdout",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("expected exactly one manifest file")
+					}
+
+					value, err := secretValue(c, "key")
+					if err != nil {
+						return err
+					}
+
+					if value == "" {
+						return errors.New("an operator key or key file is required")
+					}
+
+					key, err := decodePrivateKey(value)
+					if err != nil {
+						return errors.Wrap(err, "cannot decode operator key")
+					}
+
+					content, err := os.ReadFile(c.Args().First())
+					if err != nil {
+						return errors.Wrap(err, "cannot read manifest")
+					}
+
+					signed, err := manifest.Sign(key, content)
+					if err != nil {
+						return errors.Wrap(err, "cannot sign manifest")
+					}
+
+					if c.String("out") == "" {
+						return printJSON(signed)
+					}
+
+					signedBytes, err := json.MarshalIndent(signed, "", "  ")
+					if err != nil {
+						return errors.Wrap(err, "cannot encode manifest")
+					}
+
+					return errors.Wrap(os.WriteFile(c.String("out"), signedBytes, 0o600), "cannot write manifest")
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"github.com/data-preservation-programs/filsigner-relayed/approval"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/cosign"
+	"github.com/data-preservation-programs/filsigner-relayed/datacap"
+	"github.com/data-preservation-programs/filsigner-relayed/epochwindow"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/data-preservation-programs/filsigner-relayed/replication"
+	"github.com/data-preservation-programs/filsigner-relayed/server"
+	"github.com/data-preservation-programs/filsigner-relayed/webhook"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"os"
+	"path/filepath"
+)
+
+var serverOptionFlags = append([]cli.Flag{
+	signKeyFileFlag,
+	signKeyDirFlag,
+	keystoreFlag,
+	walletMessageTypeFlag,
+	chainMessageRuleFlag,
+	removeDataCapRuleFlag,
+	&cli.StringFlag{
+		Name:    "data-dir",
+		Usage:   "The directory to keep the audit trail and the approval queue in. Audit storage is disabled if not set",
+		EnvVars: []string{"DATA_DIR"},
+	},
+	&cli.UintFlag{
+		Name:    "network-version",
+		Usage:   "The network version to decode deal proposals for, unless the request sets it. The latest supported market actor is used if not set",
+		EnvVars: []string{"NETWORK_VERSION"},
+	},
+	&cli.Int64Flag{
+		Name:    "start-min-lead",
+		Usage:   "The minimum number of epochs between the current epoch and the proposal start epoch. Setting any epoch window flag enables the window, which never signs proposals starting in the past",
+		EnvVars: []string{"START_MIN_LEAD"},
+	},
+	&cli.Int64Flag{
+		Name:    "start-max-lead",
+		Usage:   "The maximum number of epochs between the current epoch and the proposal start epoch. Zero disables the limit",
+		Value:   14 * builtin.EpochsInDay,
+		EnvVars: []string{"START_MAX_LEAD"},
+	},
+	&cli.Int64Flag{
+		Name:    "min-duration",
+		Usage:   "The minimum deal duration in epochs. Zero disables the limit",
+		Value:   int64(filmarket.DealMinDuration),
+		EnvVars: []string{"MIN_DURATION"},
+	},
+	&cli.Int64Flag{
+		Name:    "max-duration",
+		Usage:   "The maximum deal duration in epochs. Zero disables the limit",
+		Value:   int64(filmarket.DealMaxDuration),
+		EnvVars: []string{"MAX_DURATION"},
+	},
+	&cli.Int64Flag{
+		Name:    "end-max-lead",
+		Usage:   "The maximum number of epochs between the current epoch and the proposal end epoch, as limited by the sector expiration. Zero disables the limit",
+		Value:   int64(miner.MaxSectorExpirationExtension + 14*builtin.EpochsInDay),
+		EnvVars: []string{"END_MAX_LEAD"},
+	},
+	&cli.StringSliceFlag{
+		Name:    "datacap-budget",
+		Usage:   "Limit the verified piece size signed within a rolling window, as <wallet address or *>:<wallet|requester|provider>:<window>:<limit>, such as *:requester:24h:100TiB",
+		EnvVars: []string{"DATACAP_BUDGETS"},
+	},
+	&cli.IntFlag{
+		Name:    "max-replicas",
+		Usage:   "The maximum number of deals signed for a piece. Zero disables the limit",
+		EnvVars: []string{"MAX_REPLICAS"},
+	},
+	&cli.IntFlag{
+		Name:    "max-replicas-per-provider",
+		Usage:   "The maximum number of deals signed for a piece with one provider. Zero disables the limit",
+		EnvVars: []string{"MAX_REPLICAS_PER_PROVIDER"},
+	},
+	&cli.IntFlag{
+		Name:    "max-replicas-per-group",
+		Usage:   "The maximum number of deals signed for a piece with the providers of one group. Zero disables the limit",
+		EnvVars: []string{"MAX_REPLICAS_PER_GROUP"},
+	},
+	&cli.StringSliceFlag{
+		Name:    "provider-group",
+		Usage:   "Put a provider in a group, such as its organization, as <provider address>:<group>. Providers not in a group are a group of their own",
+		EnvVars: []string{"PROVIDER_GROUPS"},
+	},
+	&cli.StringFlag{
+		Name:    "piece-manifest",
+		Usage:   "Only sign proposals for the pieces of this signed manifest, as created by 'filsigner manifest sign'. It is read again when the server reloads",
+		EnvVars: []string{"PIECE_MANIFEST"},
+	},
+	&cli.StringSliceFlag{
+		Name:    "piece-manifest-operator",
+		Usage:   "The peer ID of an operator key allowed to sign the piece manifest",
+		EnvVars: []string{"PIECE_MANIFEST_OPERATORS"},
+	},
+	&cli.StringFlag{
+		Name:    "admin-socket",
+		Usage:   "The path of the unix socket to serve operator commands such as 'filsigner approvals' on",
+		EnvVars: []string{"ADMIN_SOCKET"},
+	},
+	&cli.Uint64Flag{
+		Name:    "approval-piece-size-above",
+		Usage:   "Require manual approval for proposals with a padded piece size above this value",
+		EnvVars: []string{"APPROVAL_PIECE_SIZE_ABOVE"},
+	},
+	&cli.StringFlag{
+		Name:    "approval-price-above",
+		Usage:   "Require manual approval for proposals with a storage price per epoch (attoFIL) above this value",
+		EnvVars: []string{"APPROVAL_PRICE_ABOVE"},
+	},
+	&cli.BoolFlag{
+		Name:    "approval-new-providers",
+		Usage:   "Require manual approval for proposals to providers that have not been signed for before",
+		EnvVars: []string{"APPROVAL_NEW_PROVIDERS"},
+	},
+	&cli.StringSliceFlag{
+		Name:    "cosign-wallet",
+		Usage:   "Require co-signed approvals from the approver keys before signing for this wallet address",
+		EnvVars: []string{"COSIGN_WALLETS"},
+	},
+	&cli.StringSliceFlag{
+		Name:    "cosign-approver",
+		Usage:   "The peer ID of an operator key allowed to approve proposals for the co-signed wallets",
+		EnvVars: []string{"COSIGN_APPROVERS"},
+	},
+	&cli.IntFlag{
+		Name:    "cosign-min-approvals",
+		Usage:   "The number of distinct approvers required for the co-signed wallets",
+		Value:   1,
+		EnvVars: []string{"COSIGN_MIN_APPROVALS"},
+	},
+	&cli.StringSliceFlag{
+		Name:    "webhook",
+		Usage:   "The URL to POST a JSON event to for each signature, rejection and approval decision",
+		EnvVars: []string{"WEBHOOKS"},
+	},
+	&cli.StringFlag{
+		Name:    "webhook-secret",
+		Usage:   "The secret to sign the webhook events with (HMAC-SHA256 in the X-Filsigner-Signature header)",
+		EnvVars: []string{"WEBHOOK_SECRET"},
+	},
+	&cli.StringFlag{
+		Name:    "webhook-secret-file",
+		Usage:   "The file to read the webhook secret from. It is read again when the server reloads",
+		EnvVars: []string{"WEBHOOK_SECRET_FILE"},
+	},
+}, append(rpcFlags, append(lotusWalletFlags, walletAPIFlags...)...)...)
+
+var epochWindowFlags = []string{"start-min-lead", "start-max-lead", "min-duration", "max-duration", "end-max-lead"}
+
+// serverOptions builds the optional server features from the flags.
+// The returned closer releases the opened resources.
+// Background workers needed by the options are started with the context of c.
+func serverOptions(c *cli.Context) ([]server.Option, func(), error) {
+	closer := func() {}
+	signerNetwork, err := currentNetwork(c)
+	if err != nil {
+		return nil, closer, err
+	}
+
+	resolver, err := newResolver(c)
+	if err != nil {
+		return nil, closer, err
+	}
+
+	options := []server.Option{server.WithNetwork(signerNetwork), server.WithResolver(resolver)}
+	if c.String("keystore") != "" {
+		store, err := keystore.NewDir(c.String("keystore"))
+		if err != nil {
+			return nil, closer, errors.Wrap(err, "cannot open keystore")
+		}
+
+		options = append(options, server.WithKeystore(store))
+	}
+
+	files, err := secretKeystore(c)
+	if err != nil {
+		return nil, closer, err
+	}
+	if files != nil {
+		options = append(options, server.WithKeystore(files))
+	}
+
+	lotus, err := lotusKeystore(c)
+	if err != nil {
+		return nil, closer, err
+	}
+	if lotus != nil {
+		options = append(options, server.WithKeystore(lotus))
+	}
+
+	msgTypes, err := walletAPITypes(c)
+	if err != nil {
+		return nil, closer, err
+	}
+	options = append(options, server.WithWalletAPITypes(msgTypes))
+
+	allowedTypes, err := walletMessageTypes(c.StringSlice("wallet-message-type"))
+	if err != nil {
+		return nil, closer, err
+	}
+	options = append(options, server.WithMessageTypes(allowedTypes))
+
+	chainRules, err := chainMessageRules(c.StringSlice("chain-message-rule"))
+	if err != nil {
+		return nil, closer, err
+	}
+	options = append(options, server.WithChainMessageRules(chainRules))
+
+	removalRules, err := removeDataCapRules(c.StringSlice("remove-datacap-rule"))
+	if err != nil {
+		return nil, closer, err
+	}
+	options = append(options, server.WithRemoveDataCapRules(removalRules))
+	options = append(options, server.WithNetworkVersion(network.Version(c.Uint("network-version"))))
+	var budgets []datacap.Budget
+	for _, value := range c.StringSlice("datacap-budget") {
+		budget, err := datacap.ParseBudget(value)
+		if err != nil {
+			return nil, closer, errors.Wrap(err, "cannot decode datacap budget")
+		}
+		budgets = append(budgets, budget)
+	}
+	if len(budgets) > 0 && c.String("data-dir") == "" {
+		return nil, closer, errors.New("datacap budgets require a data directory to keep the signed datacap across restarts")
+	}
+	options = append(options, server.WithDataCapBudgets(budgets))
+	limits := replication.Limits{
+		MaxReplicas:    c.Int("max-replicas"),
+		MaxPerProvider: c.Int("max-replicas-per-provider"),
+		MaxPerGroup:    c.Int("max-replicas-per-group"),
+		Groups:         make(map[address.Address]string),
+	}
+	for _, value := range c.StringSlice("provider-group") {
+		provider, group, err := replication.ParseGroup(value)
+		if err != nil {
+			return nil, closer, errors.Wrap(err, "cannot decode provider group")
+		}
+		limits.Groups[provider] = group
+	}
+	if limits.Enabled() && c.String("data-dir") == "" {
+		return nil, closer, errors.New("replication limits require a data directory to keep the signed replicas across restarts")
+	}
+	options = append(options, server.WithReplicationLimits(limits))
+	if c.String("piece-manifest") != "" {
+		var operators []peer.ID
+		for _, operator := range c.StringSlice("piece-manifest-operator") {
+			operatorID, err := peer.Decode(operator)
+			if err != nil {
+				return nil, closer, errors.Wrapf(err, "cannot decode piece manifest operator %s", operator)
+			}
+			operators = append(operators, operatorID)
+		}
+		options = append(options, server.WithPieceManifest(c.String("piece-manifest"), operators))
+	}
+	// The epoch window is only enforced once one of its flags is set, with the defaults for the others
+	for _, name := range epochWindowFlags {
+		if !c.IsSet(name) {
+			continue
+		}
+
+		if signerNetwork.Genesis.IsZero() {
+			return nil, closer, errors.Errorf("epoch windows require the genesis time of %s, give --genesis-timestamp", signerNetwork.Name)
+		}
+
+		options = append(options, server.WithEpochWindow(epochwindow.Window{
+			MinLead:     abi.ChainEpoch(c.Int64("start-min-lead")),
+			MaxLead:     abi.ChainEpoch(c.Int64("start-max-lead")),
+			MinDuration: abi.ChainEpoch(c.Int64("min-duration")),
+			MaxDuration: abi.ChainEpoch(c.Int64("max-duration")),
+			MaxEndLead:  abi.ChainEpoch(c.Int64("end-max-lead")),
+		}))
+		break
+	}
+
+	dataDir := c.String("data-dir")
+	if dataDir != "" {
+		err := os.MkdirAll(dataDir, 0o700)
+		if err != nil {
+			return nil, closer, errors.Wrap(err, "cannot create data directory")
+		}
+
+		auditPath := filepath.Join(dataDir, "audit.log")
+		auditLog, err := audit.Open(auditPath)
+		if err != nil {
+			return nil, closer, errors.Wrap(err, "cannot open audit log")
+		}
+
+		closer = func() { auditLog.Close() }
+		options = append(options, server.WithAuditLog(auditLog, auditPath))
+	}
+
+	rules := approval.Rules{
+		PieceSizeAbove: abi.PaddedPieceSize(c.Uint64("approval-piece-size-above")),
+		NewProviders:   c.Bool("approval-new-providers"),
+	}
+	if c.String("approval-price-above") != "" {
+		price, err := big.FromString(c.String("approval-price-above"))
+		if err != nil {
+			return nil, closer, errors.Wrap(err, "cannot parse approval price")
+		}
+		rules.PricePerEpochAbove = &price
+	}
+
+	if rules.Enabled() {
+		if dataDir == "" {
+			return nil, closer, errors.New("manual approval requires a data directory")
+		}
+
+		queue, err := approval.NewQueue(filepath.Join(dataDir, "approvals"))
+		if err != nil {
+			return nil, closer, errors.Wrap(err, "cannot open approval queue")
+		}
+
+		options = append(options, server.WithApprovalQueue(queue, rules))
+	}
+
+	if len(c.StringSlice("cosign-wallet")) > 0 {
+		if dataDir == "" {
+			return nil, closer, errors.New("the two-person rule requires a data directory")
+		}
+
+		policy := &cosign.Policy{
+			MinApprovals: c.Int("cosign-min-approvals"),
+			Dir:          filepath.Join(dataDir, "cosign"),
+		}
+		for _, wallet := range c.StringSlice("cosign-wallet") {
+			addr, err := address.NewFromString(wallet)
+			if err != nil {
+				return nil, closer, errors.Wrapf(err, "cannot decode co-signed wallet %s", wallet)
+			}
+			policy.Wallets = append(policy.Wallets, addr)
+		}
+		for _, approver := range c.StringSlice("cosign-approver") {
+			approverID, err := peer.Decode(approver)
+			if err != nil {
+				return nil, closer, errors.Wrapf(err, "cannot decode approver %s", approver)
+			}
+			policy.Approvers = append(policy.Approvers, approverID)
+		}
+		if len(policy.Approvers) < policy.MinApprovals {
+			return nil, closer, errors.New("not enough approvers configured for the two-person rule")
+		}
+
+		options = append(options, server.WithCosignPolicy(policy))
+	}
+
+	if len(c.StringSlice("webhook")) > 0 {
+		if dataDir == "" {
+			return nil, closer, errors.New("webhooks require a data directory for the outbox")
+		}
+
+		endpoints := make([]webhook.Endpoint, len(c.StringSlice("webhook")))
+		for i, url := range c.StringSlice("webhook") {
+			endpoints[i] = webhook.Endpoint{URL: url, Secret: c.String("webhook-secret"), SecretFile: c.String("webhook-secret-file")}
+		}
+
+		notifier, err := webhook.NewNotifier(filepath.Join(dataDir, "outbox"), endpoints)
+		if err != nil {
+			return nil, closer, errors.Wrap(err, "cannot create webhook notifier")
+		}
+
+		go notifier.Run(c.Context)
+		options = append(options, server.WithWebhooks(notifier))
+	}
+
+	return options, closer, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func pingCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ping",
+		Usage: "Check that filsigner servers are reachable and measure the round trip time through each relay",
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "destination",
+				Aliases:  []string{"d"},
+				Usage:    "The peer ID of the filsigner server to ping",
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:    "relay-info",
+				Usage:   "The relay info to use to connect to the signer - this will override the default relay servers from SPADE",
+				EnvVars: []string{"RELAY_INFOS"},
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "The timeout for each relay path",
+				Value: 30 * time.Second,
+			},
+		}, identityFlags...),
+		Action: func(c *cli.Context) error {
+			identityKey, err := loadIdentity(c)
+			if err != nil {
+				return err
+			}
+
+			destinations, err := parsePeers(c.StringSlice("destination"))
+			if err != nil {
+				return errors.Wrap(err, "cannot decode destination")
+			}
+
+			relays, err := parseRelays(c, c.StringSlice("relay-info"))
+			if err != nil {
+				return err
+			}
+
+			client, err := newClient(c, identityKey, relays)
+			if err != nil {
+				return errors.Wrap(err, "cannot create client")
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(writer, "SIGNER\tRELAY\tCONNECT\tRTT\tUPTIME\tRESERVATIONS\tKEYSTORE\tERROR")
+			reachable := false
+			for _, destination := range destinations {
+				for _, result := range client.PingRelays(c.Context, destination, c.Duration("timeout")) {
+					if result.Err != nil {
+						fmt.Fprintf(writer, "%s\t%s\t%s\t-\t-\t-\t-\t%v\n", destination, result.Relay, result.ConnectTime.Round(time.Millisecond), result.Err)
+						continue
+					}
+
+					reachable = true
+					keystore := "locked"
+					if result.Status.KeystoreUnlocked {
+						keystore = "unlocked"
+					}
+
+					fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t\n", destination, result.Relay,
+						result.ConnectTime.Round(time.Millisecond), result.RTT.Round(time.Millisecond),
+						time.Duration(result.Status.UptimeSeconds)*time.Second, result.Status.Reservations, keystore)
+				}
+			}
+
+			err = writer.Flush()
+			if err != nil {
+				return errors.Wrap(err, "cannot write output")
+			}
+
+			if !reachable {
+				return errors.New("no signer is reachable")
+			}
+
+			return nil
+		},
+	}
+}
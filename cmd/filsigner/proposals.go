@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"io"
+	"os"
+)
+
+const (
+	formatAuto = "auto"
+	formatJSON = "json"
+	formatCBOR = "cbor"
+)
+
+// openInputs opens the files to read from, where no path or "-" means stdin
+func openInputs(paths []string) ([]io.ReadCloser, error) {
+	if len(paths) == 0 {
+		return []io.ReadCloser{io.NopCloser(os.Stdin)}, nil
+	}
+
+	inputs := make([]io.ReadCloser, 0, len(paths))
+	for _, path := range paths {
+		if path == "-" {
+			inputs = append(inputs, io.NopCloser(os.Stdin))
+			continue
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			for _, input := range inputs {
+				input.Close()
+			}
+			return nil, errors.Wrapf(err, "cannot open %s", path)
+		}
+
+		inputs = append(inputs, file)
+	}
+
+	return inputs, nil
+}
+
+// detectFormat peeks at the input to tell Lotus JSON from raw CBOR
+func detectFormat(reader *bufio.Reader, format string) (string, error) {
+	if format != formatAuto {
+		return format, nil
+	}
+
+	for {
+		next, err := reader.Peek(1)
+		if err != nil {
+			return "", errors.Wrap(err, "cannot detect input format")
+		}
+
+		switch next[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = reader.ReadByte()
+		case '{', '[':
+			return formatJSON, nil
+		default:
+			return formatCBOR, nil
+		}
+	}
+}
+
+// decodeStream reads all values from the input, either a JSON value, a JSON array or concatenated JSON values,
+// or concatenated CBOR values. newValue allocates the value to decode the next entry into.
+func decodeStream[T any](input io.Reader, format string, newValue func() T) ([]T, error) {
+	reader := bufio.NewReader(input)
+	format, err := detectFormat(reader, format)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var values []T
+	switch format {
+	case formatJSON:
+		decoder := json.NewDecoder(reader)
+		for {
+			raw := json.RawMessage{}
+			err = decoder.Decode(&raw)
+			if errors.Is(err, io.EOF) {
+				return values, nil
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot decode JSON")
+			}
+
+			if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+				var list []json.RawMessage
+				err = json.Unmarshal(raw, &list)
+				if err != nil {
+					return nil, errors.Wrap(err, "cannot decode JSON array")
+				}
+				for _, item := range list {
+					value := newValue()
+					err = json.Unmarshal(item, value)
+					if err != nil {
+						return nil, errors.Wrap(err, "cannot decode JSON")
+					}
+					values = append(values, value)
+				}
+				continue
+			}
+
+			value := newValue()
+			err = json.Unmarshal(raw, value)
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot decode JSON")
+			}
+			values = append(values, value)
+		}
+	case formatCBOR:
+		for {
+			_, err = reader.Peek(1)
+			if errors.Is(err, io.EOF) {
+				return values, nil
+			}
+
+			value := newValue()
+			unmarshaler, ok := any(value).(cbg.CBORUnmarshaler)
+			if !ok {
+				return nil, errors.New("value cannot be decoded from CBOR")
+			}
+
+			err = unmarshaler.UnmarshalCBOR(reader)
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot decode CBOR")
+			}
+			values = append(values, value)
+		}
+	default:
+		return nil, errors.Errorf("unsupported format %s", format)
+	}
+}
+
+// readProposals reads deal proposals in Lotus JSON or raw CBOR from the files, or stdin
+func readProposals(paths []string, format string) ([]*filmarket.DealProposal, error) {
+	inputs, err := openInputs(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var proposals []*filmarket.DealProposal
+	for i, input := range inputs {
+		decoded, err := decodeStream(input, format, func() *filmarket.DealProposal { return new(filmarket.DealProposal) })
+		input.Close()
+		if err != nil {
+			if len(paths) > i {
+				return nil, errors.Wrapf(err, "cannot read proposals from %s", paths[i])
+			}
+			return nil, errors.Wrap(err, "cannot read proposals from stdin")
+		}
+
+		proposals = append(proposals, decoded...)
+	}
+
+	return proposals, nil
+}
+
+// writeValues writes the values as JSON, one document per line, or as concatenated CBOR
+func writeValues[T cbg.CBORMarshaler](output io.Writer, format string, values []T) error {
+	for _, value := range values {
+		switch format {
+		case formatJSON:
+			content, err := json.Marshal(value)
+			if err != nil {
+				return errors.Wrap(err, "cannot encode JSON")
+			}
+
+			_, err = output.Write(append(content, '\n'))
+			if err != nil {
+				return errors.Wrap(err, "cannot write output")
+			}
+		case formatCBOR:
+			err := value.MarshalCBOR(output)
+			if err != nil {
+				return errors.Wrap(err, "cannot write CBOR")
+			}
+		default:
+			return errors.Errorf("unsupported format %s", format)
+		}
+	}
+
+	return nil
+}
+
+// createOutput opens the file to write to, where an empty path or "-" means stdout
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create %s", path)
+	}
+
+	return file, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/ipfs/go-cid"
+	"testing"
+)
+
+func testProposal(price int64) *filmarket.DealProposal {
+	return &filmarket.DealProposal{
+		PieceCID:             cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+		PieceSize:            256,
+		Client:               address.TestAddress,
+		Provider:             address.TestAddress2,
+		Label:                filmarket.EmptyDealLabel,
+		StoragePricePerEpoch: big.NewInt(price),
+		ProviderCollateral:   big.Zero(),
+		ClientCollateral:     big.Zero(),
+	}
+}
+
+func newProposal() *filmarket.DealProposal {
+	return new(filmarket.DealProposal)
+}
+
+func TestDecodeStream(t *testing.T) {
+	proposals := []*filmarket.DealProposal{testProposal(1), testProposal(2)}
+
+	array, err := json.Marshal(proposals)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	lines := new(bytes.Buffer)
+	err = writeValues(lines, formatJSON, proposals)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	concatenated := new(bytes.Buffer)
+	err = writeValues(concatenated, formatCBOR, proposals)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	for name, input := range map[string][]byte{
+		"json array": array,
+		"json lines": lines.Bytes(),
+		"cbor":       concatenated.Bytes(),
+	} {
+		decoded, err := decodeStream(bytes.NewReader(input), formatAuto, newProposal)
+		if err != nil {
+			t.Fatalf("%s: err is not null: %v", name, err)
+		}
+
+		if len(decoded) != 2 || !decoded[1].StoragePricePerEpoch.Equals(big.NewInt(2)) {
+			t.Fatalf("%s: unexpected proposals: %v", name, decoded)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	client2 "github.com/data-preservation-programs/filsigner-relayed/client"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+func signCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "sign",
+		Usage:     "Request signatures from filsigner servers for deal proposals read from files or stdin",
+		ArgsUsage: "[proposal files, or - for stdin]",
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "destination",
+				Aliases:  []string{"d"},
+				Usage:    "The peer ID to send the deal proposals to. Specify multiple redundant signers holding the same wallet to fail over between them",
+				Required: true,
+			},
+			&cli.DurationFlag{
+				Name:  "hedge-delay",
+				Usage: "How long to wait for a signer before also asking the next destination. Zero only fails over on error",
+			},
+			&cli.StringSliceFlag{
+				Name:    "relay-info",
+				Usage:   "The relay info to use to connect to the signer - this will override the default relay servers from SPADE",
+				EnvVars: []string{"RELAY_INFOS"},
+			},
+			&cli.StringFlag{
+				Name:  "input-format",
+				Usage: "The format of the proposals: json (Lotus JSON), cbor or auto",
+				Value: formatAuto,
+			},
+			&cli.StringFlag{
+				Name:  "output-format",
+				Usage: "The format of the signed ClientDealProposals: json (one per line) or cbor",
+				Value: formatJSON,
+			},
+			&cli.StringFlag{
+				Name:  "out",
+				Usage: "Write the signed proposals to this file instead of stdout",
+			},
+		}, identityFlags...),
+		Action: func(c *cli.Context) error {
+			log := logging.Logger("sign")
+			identityKey, err := loadIdentity(c)
+			if err != nil {
+				return err
+			}
+
+			destinations, err := parsePeers(c.StringSlice("destination"))
+			if err != nil {
+				return errors.Wrap(err, "cannot decode destination")
+			}
+
+			relays, err := parseRelays(c, c.StringSlice("relay-info"))
+			if err != nil {
+				return err
+			}
+
+			proposals, err := readProposals(c.Args().Slice(), c.String("input-format"))
+			if err != nil {
+				return err
+			}
+
+			if len(proposals) == 0 {
+				return errors.New("no proposals to sign")
+			}
+
+			client, err := newClient(c, identityKey, relays)
+			if err != nil {
+				return errors.Wrap(err, "cannot create client")
+			}
+			client.HedgeDelay = c.Duration("hedge-delay")
+
+			signed := make([]*filmarket.ClientDealProposal, 0, len(proposals))
+			failed := 0
+			for i, proposal := range proposals {
+				signature, signer, err := client.SignProposalWithFailover(c.Context, destinations, *proposal)
+				var requestErr *client2.RequestError
+				if errors.As(err, &requestErr) && requestErr.StatusCode == model.PendingApproval {
+					log.Warnw("proposal is pending manual approval", "index", i, "ticket", requestErr.Ticket)
+					failed++
+					continue
+				}
+				if err != nil {
+					log.Errorw("cannot sign proposal", "index", i, "error", err)
+					failed++
+					continue
+				}
+
+				log.Infow("signed proposal", "index", i, "signer", signer.String())
+				signed = append(signed, &filmarket.ClientDealProposal{
+					Proposal:        *proposal,
+					ClientSignature: *signature,
+				})
+			}
+
+			output, err := createOutput(c.String("out"))
+			if err != nil {
+				return err
+			}
+			defer output.Close()
+
+			err = writeValues(output, c.String("output-format"), signed)
+			if err != nil {
+				return err
+			}
+
+			if failed > 0 {
+				return errors.Errorf("%d of %d proposals were not signed", failed, len(proposals))
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/base64"
+	"github.com/data-preservation-programs/filsigner-relayed/chainmsg"
+	"github.com/data-preservation-programs/filsigner-relayed/message"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/removedatacap"
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"io"
+	"strings"
+)
+
+var walletMessageTypeFlag = &cli.StringSliceFlag{
+	Name:    "wallet-message-type",
+	Usage:   "Allow the wallet to sign a message type of the sign protocol, as <wallet address>:<type>. Wallets that are not listed only sign deal proposals",
+	EnvVars: []string{"WALLET_MESSAGE_TYPES"},
+}
+
+var chainMessageRuleFlag = &cli.StringSliceFlag{
+	Name:    "chain-message-rule",
+	Usage:   "Allow the wallet to sign chain messages calling a method of an actor, as <wallet address>:<to>:<method>[:<max value in attoFIL>[:<max fee in attoFIL>]]. The fee is capped at 0.07 FIL if it is not set",
+	EnvVars: []string{"CHAIN_MESSAGE_RULES"},
+}
+
+// chainMessageRules decodes the chain message rules of each wallet
+func chainMessageRules(values []string) (map[address.Address][]chainmsg.Rule, error) {
+	rules := make(map[address.Address][]chainmsg.Rule)
+	for _, value := range values {
+		wallet, ruleValue, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, errors.Errorf("cannot decode chain message rule %s, expected <wallet address>:<to>:<method>[:<max value>[:<max fee>]]", value)
+		}
+
+		addr, err := address.NewFromString(wallet)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode wallet %s", wallet)
+		}
+
+		rule, err := chainmsg.ParseRule(ruleValue)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode chain message rule")
+		}
+
+		rules[addr] = append(rules[addr], rule)
+	}
+
+	return rules, nil
+}
+
+var removeDataCapRuleFlag = &cli.StringSliceFlag{
+	Name:    "remove-datacap-rule",
+	Usage:   "Allow the verifier wallet to sign datacap removals for a verified client, as <wallet address>:<client ID address>[:<max amount in bytes>]",
+	EnvVars: []string{"REMOVE_DATACAP_RULES"},
+}
+
+// removeDataCapRules decodes the datacap removal rules of each verifier wallet
+func removeDataCapRules(values []string) (map[address.Address][]removedatacap.Rule, error) {
+	rules := make(map[address.Address][]removedatacap.Rule)
+	for _, value := range values {
+		wallet, ruleValue, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, errors.Errorf("cannot decode remove datacap rule %s, expected <wallet address>:<client ID address>[:<max amount>]", value)
+		}
+
+		addr, err := address.NewFromString(wallet)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode wallet %s", wallet)
+		}
+
+		rule, err := removedatacap.ParseRule(ruleValue)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode remove datacap rule")
+		}
+
+		rules[addr] = append(rules[addr], rule)
+	}
+
+	return rules, nil
+}
+
+// walletMessageTypes decodes the message types allowed for each wallet
+func walletMessageTypes(values []string) (map[address.Address][]string, error) {
+	allowed := make(map[address.Address][]string)
+	for _, value := range values {
+		wallet, msgType, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, errors.Errorf("cannot decode wallet message type %s, expected <wallet address>:<type>", value)
+		}
+
+		addr, err := address.NewFromString(wallet)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode wallet %s", wallet)
+		}
+
+		allowed[addr] = append(allowed[addr], msgType)
+	}
+
+	return allowed, nil
+}
+
+func signMessageCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "sign-message",
+		Usage:     "Request the signature of a typed message from a filsigner server",
+		ArgsUsage: "[payload file, or - for stdin]",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "destination",
+				Aliases:  []string{"d"},
+				Usage:    "The peer ID of the signer",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "wallet",
+				Usage:    "The wallet address to sign with",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "The message type of the payload: " + strings.Join(model.MessageTypes, ", "),
+				Value: model.MessageTypeRaw,
+			},
+			&cli.StringFlag{
+				Name:  "data",
+				Usage: "The payload to sign, instead of reading it from a file or stdin. Deal status requests take the deal UUID",
+			},
+			&cli.StringFlag{
+				Name:  "encoding",
+				Usage: "The encoding of the payload: hex, base64, raw or auto",
+				Value: encodingRaw,
+			},
+			&cli.StringSliceFlag{
+				Name:    "relay-info",
+				Usage:   "The relay info to use to connect to the signer - this will override the default relay servers from SPADE",
+				EnvVars: []string{"RELAY_INFOS"},
+			},
+		}, identityFlags...),
+		Action: func(c *cli.Context) error {
+			identityKey, err := loadIdentity(c)
+			if err != nil {
+				return err
+			}
+
+			destinations, err := parsePeers([]string{c.String("destination")})
+			if err != nil {
+				return errors.Wrap(err, "cannot decode destination")
+			}
+
+			wallet, err := address.NewFromString(c.String("wallet"))
+			if err != nil {
+				return errors.Wrap(err, "cannot decode wallet")
+			}
+
+			relays, err := parseRelays(c, c.StringSlice("relay-info"))
+			if err != nil {
+				return err
+			}
+
+			input := []byte(c.String("data"))
+			if !c.IsSet("data") {
+				inputs, err := openInputs(c.Args().Slice())
+				if err != nil {
+					return err
+				}
+
+				if len(inputs) != 1 {
+					for _, input := range inputs {
+						input.Close()
+					}
+					return errors.New("expected a single input")
+				}
+
+				input, err = io.ReadAll(inputs[0])
+				inputs[0].Close()
+				if err != nil {
+					return errors.Wrap(err, "cannot read input")
+				}
+			}
+
+			var payload []byte
+			if c.String("type") == model.MessageTypeDealStatusRequest {
+				uuid, err := message.ParseUUID(strings.TrimSpace(string(input)))
+				if err != nil {
+					return errors.Wrap(err, "cannot decode deal uuid")
+				}
+				payload = uuid[:]
+			} else {
+				payload, err = decodeBytes(input, c.String("encoding"))
+				if err != nil {
+					return err
+				}
+			}
+
+			client, err := newClient(c, identityKey, relays)
+			if err != nil {
+				return errors.Wrap(err, "cannot create client")
+			}
+
+			if c.String("type") == model.MessageTypeChainMessage {
+				msg, err := chainmsg.Decode(payload)
+				if err != nil {
+					return err
+				}
+				if msg.From != wallet {
+					return errors.Errorf("message is sent from %s, not from the wallet %s", msg.From, wallet)
+				}
+
+				signed, err := client.SignChainMessage(c.Context, destinations[0], msg)
+				if err != nil {
+					return errors.Wrap(err, "cannot sign chain message")
+				}
+
+				signedBytes, err := cborutil.Dump(signed)
+				if err != nil {
+					return errors.Wrap(err, "cannot marshal signed message")
+				}
+
+				msgCid, err := msg.Cid()
+				if err != nil {
+					return err
+				}
+
+				return printJSON(map[string]string{
+					"Type":          c.String("type"),
+					"Wallet":        wallet.String(),
+					"CID":           msgCid.String(),
+					"SignedMessage": base64.StdEncoding.EncodeToString(signedBytes),
+				})
+			}
+
+			if c.String("type") == model.MessageTypeRemoveDataCapProposal {
+				proposal, err := removedatacap.Decode(payload)
+				if err != nil {
+					return err
+				}
+
+				request, err := client.SignRemoveDataCapProposal(c.Context, destinations[0], wallet, proposal)
+				if err != nil {
+					return errors.Wrap(err, "cannot sign remove datacap proposal")
+				}
+
+				requestBytes, err := cborutil.Dump(request)
+				if err != nil {
+					return errors.Wrap(err, "cannot marshal remove datacap request")
+				}
+
+				return printJSON(map[string]string{
+					"Type":                 c.String("type"),
+					"Wallet":               wallet.String(),
+					"RemoveDataCapRequest": base64.StdEncoding.EncodeToString(requestBytes),
+				})
+			}
+
+			signature, err := client.SignMessage(c.Context, destinations[0], c.String("type"), wallet, payload)
+			if err != nil {
+				return errors.Wrap(err, "cannot sign message")
+			}
+
+			signatureBytes, err := signature.MarshalBinary()
+			if err != nil {
+				return errors.Wrap(err, "cannot marshal signature")
+			}
+
+			return printJSON(map[string]string{
+				"Type":      c.String("type"),
+				"Wallet":    wallet.String(),
+				"Signature": base64.StdEncoding.EncodeToString(signatureBytes),
+			})
+		},
+	}
+}
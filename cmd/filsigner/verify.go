@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/chain"
+	"github.com/data-preservation-programs/filsigner-relayed/verify"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var rpcFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "rpc",
+		Usage:   "The Lotus JSON-RPC endpoint used to resolve ID addresses. Defaults to the public endpoint of the network",
+		EnvVars: []string{"CHAIN_RPC"},
+	},
+	&cli.StringFlag{
+		Name:    "rpc-token",
+		Usage:   "The API token for the Lotus JSON-RPC endpoint",
+		EnvVars: []string{"CHAIN_RPC_TOKEN"},
+	},
+	&cli.StringFlag{
+		Name:    "rpc-token-file",
+		Usage:   "The file to read the API token for the Lotus JSON-RPC endpoint from",
+		EnvVars: []string{"CHAIN_RPC_TOKEN_FILE"},
+	},
+}
+
+// newResolver creates the address resolver for the Lotus JSON-RPC endpoint of the flags
+func newResolver(c *cli.Context) (*chain.RPCResolver, error) {
+	token, err := secretValue(c, "rpc-token")
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := c.String("rpc")
+	if endpoint == "" {
+		network, err := currentNetwork(c)
+		if err != nil {
+			return nil, err
+		}
+		endpoint = network.RPCEndpoint
+	}
+
+	return chain.NewRPCResolver(endpoint, token), nil
+}
+
+// decodeSignature decodes a signature in the Lotus binary form (type byte followed by the data), encoded in hex or base64
+func decodeSignature(value string) (*filcrypto.Signature, error) {
+	signatureBytes, err := hex.DecodeString(value)
+	if err != nil {
+		signatureBytes, err = base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, errors.New("signature is neither hex nor base64")
+		}
+	}
+
+	signature := new(filcrypto.Signature)
+	err = signature.UnmarshalBinary(signatureBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot unmarshal signature")
+	}
+
+	return signature, nil
+}
+
+func verifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "verify",
+		Usage:     "Verify client signatures of deal proposals offline",
+		ArgsUsage: "[signed ClientDealProposal files, or proposal files with --signature, or - for stdin]",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "signature",
+				Usage: "The signature (hex or base64) of the proposal. If not set, the inputs are ClientDealProposals",
+			},
+			&cli.StringFlag{
+				Name:  "input-format",
+				Usage: "The format of the inputs: json (Lotus JSON), cbor or auto",
+				Value: formatAuto,
+			},
+		}, rpcFlags...),
+		Action: func(c *cli.Context) error {
+			resolver, err := newResolver(c)
+			if err != nil {
+				return err
+			}
+
+			var signed []*filmarket.ClientDealProposal
+			if c.String("signature") != "" {
+				signature, err := decodeSignature(c.String("signature"))
+				if err != nil {
+					return err
+				}
+
+				proposals, err := readProposals(c.Args().Slice(), c.String("input-format"))
+				if err != nil {
+					return err
+				}
+
+				if len(proposals) != 1 {
+					return errors.Errorf("expected exactly one proposal for the signature, got %d", len(proposals))
+				}
+
+				signed = append(signed, &filmarket.ClientDealProposal{Proposal: *proposals[0], ClientSignature: *signature})
+			} else {
+				inputs, err := openInputs(c.Args().Slice())
+				if err != nil {
+					return err
+				}
+
+				for _, input := range inputs {
+					decoded, err := decodeStream(input, c.String("input-format"), func() *filmarket.ClientDealProposal {
+						return new(filmarket.ClientDealProposal)
+					})
+					input.Close()
+					if err != nil {
+						return errors.Wrap(err, "cannot read signed proposals")
+					}
+					signed = append(signed, decoded...)
+				}
+			}
+
+			invalid := 0
+			for _, proposal := range signed {
+				result, err := verify.ClientDealProposal(c.Context, resolver, proposal)
+				if err != nil {
+					return err
+				}
+
+				if !result.Valid {
+					invalid++
+				}
+
+				content, err := json.Marshal(result)
+				if err != nil {
+					return errors.Wrap(err, "cannot encode result")
+				}
+
+				//nolint:forbidigo
+				fmt.Println(string(content))
+			}
+
+			if invalid > 0 {
+				return errors.Errorf("%d of %d signatures are not valid", invalid, len(signed))
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/chain"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/filecoin-project/go-address"
+	"github.com/jsign/go-filsigner/wallet"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"io"
+)
+
+var keystoreFlag = &cli.StringFlag{
+	Name:    "keystore",
+	Usage:   "The keystore directory holding wallet keys, in the Lotus keystore layout",
+	EnvVars: []string{"KEYSTORE"},
+}
+
+var signKeyFileFlag = &cli.StringSliceFlag{
+	Name:    "sign-key-file",
+	Usage:   "A file with private keys to sign with, as exported by 'lotus wallet export', one or more per line",
+	EnvVars: []string{"SIGN_KEYS_FILE"},
+}
+
+var signKeyDirFlag = &cli.StringFlag{
+	Name:    "sign-key-dir",
+	Usage:   "A directory of files with private keys to sign with, such as a Docker or Kubernetes secret mount",
+	EnvVars: []string{"SIGN_KEY_DIR"},
+}
+
+var lotusWalletFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "lotus-wallet-api",
+		Usage:   "The JSON-RPC endpoint of a lotus-wallet daemon or Lotus node to delegate signing to, such as http://127.0.0.1:1234/rpc/v0",
+		EnvVars: []string{"LOTUS_WALLET_API"},
+	},
+	&cli.StringFlag{
+		Name:    "lotus-wallet-token",
+		Usage:   "The API token with the sign permission for the Lotus wallet API",
+		EnvVars: []string{"LOTUS_WALLET_TOKEN"},
+	},
+	&cli.StringFlag{
+		Name:    "lotus-wallet-token-file",
+		Usage:   "The file to read the API token for the Lotus wallet API from",
+		EnvVars: []string{"LOTUS_WALLET_TOKEN_FILE"},
+	},
+	&cli.BoolFlag{
+		Name:    "lotus-full-node",
+		Usage:   "The Lotus wallet API is a full node, which signs without being told the message type",
+		EnvVars: []string{"LOTUS_FULL_NODE"},
+	},
+}
+
+var walletFlags = append([]cli.Flag{
+	&cli.StringSliceFlag{
+		Name:    "sign-key",
+		Aliases: []string{"s"},
+		Usage:   "The private key of the address to sign with, as exported by 'lotus wallet export'",
+		EnvVars: []string{"SIGN_KEYS"},
+	},
+	signKeyFileFlag,
+	signKeyDirFlag,
+	keystoreFlag,
+}, append(lotusWalletFlags, rpcFlags...)...)
+
+// hasWalletSources reports whether any of the flags to sign with are set
+func hasWalletSources(c *cli.Context) bool {
+	return len(c.StringSlice("sign-key")) > 0 || len(c.StringSlice("sign-key-file")) > 0 ||
+		c.String("sign-key-dir") != "" || c.String("keystore") != "" || c.String("lotus-wallet-api") != ""
+}
+
+// lotusKeystore returns the keystore delegating to the Lotus wallet API of the flags, or nil if it is not set
+func lotusKeystore(c *cli.Context) (*keystore.Lotus, error) {
+	if c.String("lotus-wallet-api") == "" {
+		return nil, nil
+	}
+
+	token, err := secretValue(c, "lotus-wallet-token")
+	if err != nil {
+		return nil, err
+	}
+
+	return keystore.NewLotus(c.String("lotus-wallet-api"), token, c.Bool("lotus-full-node")), nil
+}
+
+// secretKeystore returns the keystore of the key files given with the flags, or nil if there are none
+func secretKeystore(c *cli.Context) (*keystore.Files, error) {
+	if len(c.StringSlice("sign-key-file")) == 0 && c.String("sign-key-dir") == "" {
+		return nil, nil
+	}
+
+	files, err := keystore.NewFiles(c.StringSlice("sign-key-file"), c.String("sign-key-dir"))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read sign key files")
+	}
+
+	return files, nil
+}
+
+var keyTypeFlag = &cli.StringFlag{
+	Name:  "type",
+	Usage: "The key type: secp256k1 or bls",
+	Value: string(wallet.KTSecp256k1),
+}
+
+// walletEntry is a wallet as printed by the wallet commands
+type walletEntry struct {
+	Address   string `json:"address"`
+	IDAddress string `json:"idAddress,omitempty"`
+	Type      string `json:"type"`
+}
+
+// openKeystore combines the keys from the flags with the keystore directory, the same way 'run' does
+func openKeystore(c *cli.Context) (keystore.Multi, error) {
+	static, err := keystore.NewStatic(c.StringSlice("sign-key"))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode sign keys")
+	}
+
+	stores := keystore.Multi{static}
+	files, err := secretKeystore(c)
+	if err != nil {
+		return nil, err
+	}
+	if files != nil {
+		stores = append(stores, files)
+	}
+
+	lotus, err := lotusKeystore(c)
+	if err != nil {
+		return nil, err
+	}
+	if lotus != nil {
+		stores = append(stores, lotus)
+	}
+
+	if c.String("keystore") != "" {
+		dir, err := keystore.NewDir(c.String("keystore"))
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot open keystore")
+		}
+
+		// New and imported keys go to the keystore directory
+		stores = append(keystore.Multi{dir}, stores...)
+	}
+
+	return stores, nil
+}
+
+func newWalletEntry(ctx context.Context, resolver chain.Resolver, addr address.Address) walletEntry {
+	entry := walletEntry{Address: addr.String()}
+	switch addr.Protocol() {
+	case address.SECP256K1:
+		entry.Type = string(wallet.KTSecp256k1)
+	case address.BLS:
+		entry.Type = string(wallet.KTBLS)
+	}
+
+	// The ID address only exists once the wallet has been used on chain
+	idAddr, err := resolver.LookupID(ctx, addr)
+	if err == nil {
+		entry.IDAddress = idAddr.String()
+	}
+
+	return entry
+}
+
+// parseKeyInfo reads a key exported by 'lotus wallet export', a Lotus keystore file (JSON KeyInfo),
+// or a raw hex or base64 private key of the key type
+func parseKeyInfo(input []byte, keyType string) (*keystore.KeyInfo, error) {
+	input = bytes.TrimSpace(input)
+	content := input
+	if decoded, err := hex.DecodeString(string(input)); err == nil {
+		content = decoded
+	}
+
+	if bytes.HasPrefix(content, []byte("{")) {
+		key := new(keystore.KeyInfo)
+		err := json.Unmarshal(content, key)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode key info")
+		}
+		return key, nil
+	}
+
+	if len(content) != 32 {
+		decoded, err := base64.StdEncoding.DecodeString(string(input))
+		if err != nil || len(decoded) != 32 {
+			return nil, errors.New("key is neither a Lotus key nor a 32 byte hex or base64 private key")
+		}
+		content = decoded
+	}
+
+	switch wallet.KeyType(keyType) {
+	case wallet.KTSecp256k1, wallet.KTBLS:
+		return &keystore.KeyInfo{Type: wallet.KeyType(keyType), PrivateKey: content}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type %s", keyType)
+	}
+}
+
+// readKeyInput reads the key from the single file argument, or stdin
+func readKeyInput(c *cli.Context) (*keystore.KeyInfo, error) {
+	inputs, err := openInputs(c.Args().Slice())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(inputs) != 1 {
+		for _, input := range inputs {
+			input.Close()
+		}
+		return nil, errors.New("expected a single key")
+	}
+
+	content, err := io.ReadAll(inputs[0])
+	inputs[0].Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read key")
+	}
+
+	return parseKeyInfo(content, c.String("type"))
+}
+
+func walletCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "wallet",
+		Usage: "Manage the Filecoin wallet keys filsigner signs with",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "new",
+				Usage: "Generate a new wallet key in the keystore",
+				Flags: append([]cli.Flag{keyTypeFlag}, walletFlags...),
+				Action: func(c *cli.Context) error {
+					store, err := openKeystore(c)
+					if err != nil {
+						return err
+					}
+
+					key, err := keystore.Generate(wallet.KeyType(c.String("type")))
+					if err != nil {
+						return errors.Wrap(err, "cannot generate key")
+					}
+
+					addr, err := store.Import(c.Context, key)
+					if err != nil {
+						return errors.Wrap(err, "cannot store key")
+					}
+
+					resolver, err := newResolver(c)
+					if err != nil {
+						return err
+					}
+
+					return printJSON(newWalletEntry(c.Context, resolver, addr))
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "List the wallets in the keystore with their ID addresses",
+				Flags: walletFlags,
+				Action: func(c *cli.Context) error {
+					store, err := openKeystore(c)
+					if err != nil {
+						return err
+					}
+
+					addrs, err := store.List(c.Context)
+					if err != nil {
+						return errors.Wrap(err, "cannot list wallets")
+					}
+
+					resolver, err := newResolver(c)
+					if err != nil {
+						return err
+					}
+
+					entries := make([]walletEntry, 0, len(addrs))
+					for _, addr := range addrs {
+						entries = append(entries, newWalletEntry(c.Context, resolver, addr))
+					}
+
+					return printJSON(entries)
+				},
+			},
+			{
+				Name:      "import",
+				Usage:     "Import a key exported by 'lotus wallet export', a Lotus keystore file, or a raw private key into the keystore",
+				ArgsUsage: "[key file, or - for stdin]",
+				Flags:     append([]cli.Flag{keyTypeFlag}, walletFlags...),
+				Action: func(c *cli.Context) error {
+					store, err := openKeystore(c)
+					if err != nil {
+						return err
+					}
+
+					key, err := readKeyInput(c)
+					if err != nil {
+						return err
+					}
+
+					addr, err := store.Import(c.Context, key)
+					if err != nil {
+						return errors.Wrap(err, "cannot import key")
+					}
+
+					resolver, err := newResolver(c)
+					if err != nil {
+						return err
+					}
+
+					return printJSON(newWalletEntry(c.Context, resolver, addr))
+				},
+			},
+			{
+				Name:      "export",
+				Usage:     "Print the private key of a wallet in the 'lotus wallet export' format",
+				ArgsUsage: "<address>",
+				Flags:     walletFlags,
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("expected the wallet address")
+					}
+
+					addr, err := address.NewFromString(c.Args().First())
+					if err != nil {
+						return errors.Wrap(err, "cannot decode address")
+					}
+
+					if addr.Protocol() == address.ID {
+						resolver, err := newResolver(c)
+						if err != nil {
+							return err
+						}
+
+						addr, err = resolver.AccountKey(c.Context, addr)
+						if err != nil {
+							return errors.Wrap(err, "cannot resolve key address")
+						}
+					}
+
+					store, err := openKeystore(c)
+					if err != nil {
+						return err
+					}
+
+					key, err := store.Export(c.Context, addr)
+					if err != nil {
+						return errors.Wrapf(err, "cannot export %s", addr)
+					}
+
+					exported, err := keystore.Encode(key)
+					if err != nil {
+						return err
+					}
+
+					//nolint:forbidigo
+					fmt.Println(exported)
+					return nil
+				},
+			},
+			{
+				Name:      "address",
+				Usage:     "Print the addresses of a key without importing it",
+				ArgsUsage: "[key file, or - for stdin]",
+				Flags:     append([]cli.Flag{keyTypeFlag}, rpcFlags...),
+				Action: func(c *cli.Context) error {
+					key, err := readKeyInput(c)
+					if err != nil {
+						return err
+					}
+
+					addr, err := keystore.Address(key)
+					if err != nil {
+						return errors.Wrap(err, "cannot derive address")
+					}
+
+					resolver, err := newResolver(c)
+					if err != nil {
+						return err
+					}
+
+					return printJSON(newWalletEntry(c.Context, resolver, addr))
+				},
+			},
+		},
+	}
+}
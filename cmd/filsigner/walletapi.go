@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/data-preservation-programs/filsigner-relayed/secret"
+	"github.com/data-preservation-programs/filsigner-relayed/walletapi"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+var walletAPIFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "wallet-api-listen",
+		Usage:   "The address to serve the Lotus compatible wallet API on, such as 127.0.0.1:1777. Disabled if not set",
+		EnvVars: []string{"WALLET_API_LISTEN"},
+	},
+	&cli.StringSliceFlag{
+		Name:    "wallet-api-token",
+		Usage:   "An API token for the wallet API as <requester peer ID>:<token>. Calls with the token are handled as requests of the allowed requester",
+		EnvVars: []string{"WALLET_API_TOKENS"},
+	},
+	&cli.StringFlag{
+		Name:    "wallet-api-token-file",
+		Usage:   "A file with wallet API tokens as <requester peer ID>:<token>, one or more per line",
+		EnvVars: []string{"WALLET_API_TOKENS_FILE"},
+	},
+	&cli.StringSliceFlag{
+		Name:    "wallet-api-msg-type",
+		Usage:   "The message type to sign through the wallet API: dealproposal, message (chain messages) or unknown (deal status requests)",
+		Value:   cli.NewStringSlice(string(keystore.MTDealProposal)),
+		EnvVars: []string{"WALLET_API_MSG_TYPES"},
+	},
+}
+
+// walletAPITokens decodes the wallet API tokens of the flags, mapped to their requesters
+func walletAPITokens(c *cli.Context) (map[string]peer.ID, error) {
+	values := c.StringSlice("wallet-api-token")
+	if c.String("wallet-api-token-file") != "" {
+		fileValues, err := secret.ReadList(c.String("wallet-api-token-file"))
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read wallet api tokens")
+		}
+		values = append(values, fileValues...)
+	}
+
+	if len(values) == 0 {
+		return nil, errors.New("the wallet api requires at least one token")
+	}
+
+	tokens := make(map[string]peer.ID)
+	for _, value := range values {
+		token, requester, err := walletapi.ParseToken(value)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot decode wallet api token")
+		}
+		tokens[token] = requester
+	}
+
+	return tokens, nil
+}
+
+// walletAPITypes decodes the message types allowed through the wallet API
+func walletAPITypes(c *cli.Context) ([]keystore.MsgType, error) {
+	var types []keystore.MsgType
+	for _, value := range c.StringSlice("wallet-api-msg-type") {
+		known := false
+		for _, msgType := range keystore.MsgTypes {
+			if string(msgType) == value {
+				known = true
+			}
+		}
+		if !known {
+			return nil, errors.Errorf("unknown wallet api message type %s", value)
+		}
+
+		types = append(types, keystore.MsgType(value))
+	}
+
+	return types, nil
+}
@@ -5,22 +5,22 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
+// GetDefaultRelayInfo returns the default relay servers from SPADE, which serve mainnet
 func GetDefaultRelayInfo() []peer.AddrInfo {
-	relays := make([]peer.AddrInfo, 2)
-
-	naRelayInfo, err := peer.AddrInfoFromString("/dns4/relay-na.spade.services/tcp/4001/p2p/12D3KooWBVheEM7TdvfQHNLsGy39PFuDSXnnkHyXfgH5uD1pheqv")
-	if err != nil {
-		log.Logger("config").Fatalf("failed to parse naRelayInfo: %v", err)
-	}
-
-	euRelayInfo, err := peer.AddrInfoFromString("/dns4/relay-eu.spade.services/tcp/4001/p2p/12D3KooWGxyLaT4h4XYYrcCpRVHh5N3WNTLJmCtaKHrfVz7sfTjM")
+	relays, err := Mainnet.RelayInfo()
 	if err != nil {
-		log.Logger("config").Fatalf("failed to parse euRelayInfo: %v", err)
+		log.Logger("config").Fatalf("failed to parse relay info: %v", err)
 	}
 
-	relays[0] = *naRelayInfo
-	relays[1] = *euRelayInfo
 	return relays
 }
 
 const ProtocolName = "/fil/signproposal/temppoc"
+
+const SignProtocolName = "/fil/signer/sign/1.0.0"
+
+const TicketProtocolName = "/fil/signproposal/ticket/temppoc"
+
+const InfoProtocolName = "/fil/signer/info/1.0.0"
+
+const PingProtocolName = "/fil/signer/ping/1.0.0"
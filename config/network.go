@@ -0,0 +1,109 @@
+package config
+
+import (
+	"github.com/data-preservation-programs/filsigner-relayed/chain"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// BlockDelay is the time between two epochs
+const BlockDelay = 30 * time.Second
+
+// Network describes a Filecoin network the signer and its requesters run on
+type Network struct {
+	Name string
+	// Address is the network of the address prefixes, f on mainnet and t on the others
+	Address address.Network
+	// RPCEndpoint is the default Lotus JSON-RPC endpoint used to resolve addresses
+	RPCEndpoint string
+	// Genesis is the time of epoch 0. It is zero if it is not known, as on devnets.
+	Genesis time.Time
+	// Relays are the default relay servers, if the network has any
+	Relays []string
+}
+
+var Mainnet = Network{
+	Name:        "mainnet",
+	Address:     address.Mainnet,
+	RPCEndpoint: chain.DefaultEndpoint,
+	Genesis:     time.Unix(1598306400, 0),
+	Relays: []string{
+		"/dns4/relay-na.spade.services/tcp/4001/p2p/12D3KooWBVheEM7TdvfQHNLsGy39PFuDSXnnkHyXfgH5uD1pheqv",
+		"/dns4/relay-eu.spade.services/tcp/4001/p2p/12D3KooWGxyLaT4h4XYYrcCpRVHh5N3WNTLJmCtaKHrfVz7sfTjM",
+	},
+}
+
+var Calibnet = Network{
+	Name:        "calibnet",
+	Address:     address.Testnet,
+	RPCEndpoint: "https://api.calibration.node.glif.io/rpc/v0",
+	Genesis:     time.Unix(1667326380, 0),
+}
+
+var Devnet = Network{
+	Name:        "devnet",
+	Address:     address.Testnet,
+	RPCEndpoint: "http://127.0.0.1:1234/rpc/v0",
+}
+
+// Networks are the networks the signer can run on
+var Networks = []Network{Mainnet, Calibnet, Devnet}
+
+// GetNetwork returns the network of the name
+func GetNetwork(name string) (Network, error) {
+	for _, network := range Networks {
+		if network.Name == name {
+			return network, nil
+		}
+	}
+
+	return Network{}, errors.Errorf("unknown network %s", name)
+}
+
+// Protocol returns the protocol ID of the network. Mainnet keeps the legacy protocol IDs,
+// the other networks add their name so that their requesters never reach a mainnet signer.
+func (n Network) Protocol(id protocol.ID) protocol.ID {
+	if n.Name == "" || n.Name == Mainnet.Name {
+		return id
+	}
+
+	return protocol.ID("/fil/" + n.Name + "/" + strings.TrimPrefix(string(id), "/fil/"))
+}
+
+// RelayInfo decodes the default relays of the network
+func (n Network) RelayInfo() ([]peer.AddrInfo, error) {
+	relays := make([]peer.AddrInfo, len(n.Relays))
+	for i, relay := range n.Relays {
+		info, err := peer.AddrInfoFromString(relay)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse relay info %s", relay)
+		}
+
+		relays[i] = *info
+	}
+
+	return relays, nil
+}
+
+// Epoch returns the epoch at the time
+func (n Network) Epoch(t time.Time) (abi.ChainEpoch, error) {
+	if n.Genesis.IsZero() {
+		return 0, errors.Errorf("the genesis time of %s is not known", n.Name)
+	}
+
+	return abi.ChainEpoch(t.Sub(n.Genesis) / BlockDelay), nil
+}
+
+// EpochTime returns the time the epoch starts at
+func (n Network) EpochTime(epoch abi.ChainEpoch) (time.Time, error) {
+	if n.Genesis.IsZero() {
+		return time.Time{}, errors.Errorf("the genesis time of %s is not known", n.Name)
+	}
+
+	return n.Genesis.Add(time.Duration(epoch) * BlockDelay), nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProtocol(t *testing.T) {
+	if Mainnet.Protocol(SignProtocolName) != SignProtocolName {
+		t.Fatalf("mainnet protocol changed: %s", Mainnet.Protocol(SignProtocolName))
+	}
+
+	if Calibnet.Protocol(SignProtocolName) != "/fil/calibnet/signer/sign/1.0.0" {
+		t.Fatalf("unexpected calibnet protocol: %s", Calibnet.Protocol(SignProtocolName))
+	}
+
+	if Devnet.Protocol(ProtocolName) != "/fil/devnet/signproposal/temppoc" {
+		t.Fatalf("unexpected devnet protocol: %s", Devnet.Protocol(ProtocolName))
+	}
+}
+
+func TestEpoch(t *testing.T) {
+	epoch, err := Mainnet.Epoch(time.Unix(1598306400+95, 0))
+	if err != nil || epoch != 3 {
+		t.Fatalf("unexpected epoch %d: %v", epoch, err)
+	}
+
+	start, err := Calibnet.EpochTime(2)
+	if err != nil || start.Unix() != 1667326380+60 {
+		t.Fatalf("unexpected epoch time %v: %v", start, err)
+	}
+
+	_, err = Devnet.Epoch(time.Now())
+	if err == nil {
+		t.Fatal("expected an error without a genesis time")
+	}
+}
+
+func TestGetNetwork(t *testing.T) {
+	network, err := GetNetwork("calibnet")
+	if err != nil || network.Name != Calibnet.Name {
+		t.Fatalf("unexpected network %v: %v", network, err)
+	}
+
+	_, err = GetNetwork("butterfly")
+	if err == nil {
+		t.Fatal("expected an error for an unknown network")
+	}
+}
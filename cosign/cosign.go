@@ -0,0 +1,167 @@
+package cosign
+
+import (
+	"encoding/json"
+	"github.com/filecoin-project/go-address"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// signaturePrefix separates approval signatures from any other use of the operator key
+const signaturePrefix = "filsigner-cosign-approval:"
+
+// Approval is a detached approval from an operator to sign a specific proposal,
+// or any proposal matching a constrained template
+type Approval struct {
+	// ProposalCID approves exactly this proposal
+	ProposalCID string `json:"proposalCid,omitempty"`
+	// PieceCID, Providers and Client constrain the approved proposals when no ProposalCID is given
+	PieceCID  string   `json:"pieceCid,omitempty"`
+	Providers []string `json:"providers,omitempty"`
+	Client    string   `json:"client,omitempty"`
+	// Expiry is when the approval stops being valid. It is required for templates.
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// SignedApproval is an Approval signed by an approver key.
+// The payload is kept as the exact bytes that were signed.
+type SignedApproval struct {
+	Payload   []byte `json:"payload"`
+	Approver  string `json:"approver"`
+	Signature []byte `json:"signature"`
+}
+
+func (a Approval) Validate() error {
+	if a.ProposalCID != "" {
+		_, err := cid.Decode(a.ProposalCID)
+		return errors.Wrap(err, "invalid proposal CID")
+	}
+
+	if a.PieceCID == "" {
+		return errors.New("approval must name either a proposal CID or a piece CID")
+	}
+
+	_, err := cid.Decode(a.PieceCID)
+	if err != nil {
+		return errors.Wrap(err, "invalid piece CID")
+	}
+
+	if a.Expiry.IsZero() {
+		return errors.New("template approvals must have an expiry")
+	}
+
+	for _, provider := range a.Providers {
+		_, err = address.NewFromString(provider)
+		if err != nil {
+			return errors.Wrapf(err, "invalid provider %s", provider)
+		}
+	}
+
+	if a.Client != "" {
+		_, err = address.NewFromString(a.Client)
+		if err != nil {
+			return errors.Wrap(err, "invalid client")
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the approval covers the proposal at the given time
+func (a Approval) Matches(proposal *filmarket.DealProposal, proposalCid cid.Cid, now time.Time) bool {
+	if !a.Expiry.IsZero() && now.After(a.Expiry) {
+		return false
+	}
+
+	if a.ProposalCID != "" {
+		return a.ProposalCID == proposalCid.String()
+	}
+
+	if a.PieceCID != proposal.PieceCID.String() {
+		return false
+	}
+
+	if a.Client != "" && a.Client != proposal.Client.String() {
+		return false
+	}
+
+	if len(a.Providers) == 0 {
+		return true
+	}
+
+	for _, provider := range a.Providers {
+		if provider == proposal.Provider.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign creates a detached approval with the approver private key
+func Sign(key crypto.PrivKey, approval Approval) (*SignedApproval, error) {
+	err := approval.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	approver, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get approver ID")
+	}
+
+	payload, err := json.Marshal(approval)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode approval")
+	}
+
+	signature, err := key.Sign(append([]byte(signaturePrefix), payload...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign approval")
+	}
+
+	return &SignedApproval{
+		Payload:   payload,
+		Approver:  approver.String(),
+		Signature: signature,
+	}, nil
+}
+
+// Verify checks the signature of the approver and returns the approval
+func (s SignedApproval) Verify() (*Approval, peer.ID, error) {
+	approver, err := peer.Decode(s.Approver)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "invalid approver")
+	}
+
+	publicKey, err := approver.ExtractPublicKey()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "cannot extract approver public key, use an ed25519 approver key")
+	}
+
+	valid, err := publicKey.Verify(append([]byte(signaturePrefix), s.Payload...), s.Signature)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to verify approval signature")
+	}
+
+	if !valid {
+		return nil, "", errors.New("approval signature is not valid")
+	}
+
+	approval := new(Approval)
+	err = json.Unmarshal(s.Payload, approval)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to decode approval")
+	}
+
+	err = approval.Validate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return approval, approver, nil
+}
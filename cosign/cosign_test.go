@@ -0,0 +1,162 @@
+package cosign
+
+import (
+	"crypto/rand"
+	"github.com/filecoin-project/go-address"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"os"
+	"testing"
+	"time"
+)
+
+func newApprover(t *testing.T) (crypto.PrivKey, peer.ID) {
+	t.Helper()
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	return key, id
+}
+
+func TestPolicyCheck(t *testing.T) {
+	key, approver := newApprover(t)
+	otherKey, _ := newApprover(t)
+	policy := Policy{
+		Wallets:   []address.Address{address.TestAddress},
+		Approvers: []peer.ID{approver},
+		Dir:       t.TempDir(),
+	}
+
+	proposal := &filmarket.DealProposal{
+		PieceCID: cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+		Client:   address.TestAddress,
+		Provider: address.TestAddress2,
+		Label:    filmarket.EmptyDealLabel,
+	}
+
+	err := policy.Check(proposal, time.Now())
+	if err == nil {
+		t.Fatalf("expected proposal without approval to be refused")
+	}
+
+	// Approvals from keys that are not configured are refused
+	signed, err := Sign(otherKey, Approval{PieceCID: proposal.PieceCID.String(), Expiry: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = policy.Submit(*signed)
+	if err == nil {
+		t.Fatalf("expected approval from unknown key to be refused")
+	}
+
+	signed, err = Sign(key, Approval{
+		PieceCID:  proposal.PieceCID.String(),
+		Providers: []string{address.TestAddress2.String()},
+		Expiry:    time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = policy.Submit(*signed)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = policy.Check(proposal, time.Now())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = policy.Check(proposal, time.Now().Add(2*time.Hour))
+	if err == nil {
+		t.Fatalf("expected expired approval to be refused")
+	}
+
+	proposal.Provider = address.TestAddress
+	err = policy.Check(proposal, time.Now())
+	if err == nil {
+		t.Fatalf("expected approval to not cover other providers")
+	}
+}
+
+func TestPolicyPrune(t *testing.T) {
+	key, approver := newApprover(t)
+	policy := Policy{
+		Wallets:   []address.Address{address.TestAddress},
+		Approvers: []peer.ID{approver},
+		Dir:       t.TempDir(),
+	}
+
+	proposal := &filmarket.DealProposal{
+		PieceCID: cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+		Client:   address.TestAddress,
+		Provider: address.TestAddress2,
+		Label:    filmarket.EmptyDealLabel,
+	}
+
+	proposalCid, err := proposal.Cid()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	expiry := time.Now().Add(time.Hour)
+	for _, approval := range []Approval{
+		{PieceCID: proposal.PieceCID.String(), Expiry: expiry},
+		{ProposalCID: proposalCid.String()},
+	} {
+		signed, err := Sign(key, approval)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		err = policy.Submit(*signed)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+	}
+
+	// The expired template approval is dropped, the proposal CID approval is used once
+	err = policy.Check(proposal, expiry.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	entries, err := os.ReadDir(policy.Dir)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if len(entries) != 0 || len(policy.approvals) != 0 {
+		t.Fatalf("expected expired and used approvals to be removed, got %d files and %d approvals", len(entries), len(policy.approvals))
+	}
+
+	err = policy.Check(proposal, time.Now())
+	if err == nil {
+		t.Fatalf("expected used approval to be refused")
+	}
+}
+
+func TestVerifyTamperedApproval(t *testing.T) {
+	key, _ := newApprover(t)
+	signed, err := Sign(key, Approval{ProposalCID: "bafy2bzacebbwuxiumeiinaz4twme5zk5g6s6weu5b74rzzmjhesu726dssa6w"})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	signed.Payload = []byte(`{"pieceCid":"baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"}`)
+	_, _, err = signed.Verify()
+	if err == nil {
+		t.Fatalf("expected tampered approval to be refused")
+	}
+}
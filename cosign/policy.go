@@ -0,0 +1,203 @@
+package cosign
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/filecoin-project/go-address"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy enforces the two-person rule for high-value wallets.
+// Signed approvals are kept as JSON files in Dir, either copied there by operators or submitted through the admin socket.
+// Each file is verified once and kept in memory, expired approvals are deleted and proposal CID approvals are used once.
+type Policy struct {
+	Wallets   []address.Address
+	Approvers []peer.ID
+	// MinApprovals is the number of distinct approvers required, at least one
+	MinApprovals int
+	Dir          string
+
+	lock sync.Mutex
+	// approvals are the verified approvals by file name, nil for files that are not valid approvals
+	approvals map[string]*storedApproval
+}
+
+type storedApproval struct {
+	approval *Approval
+	approver peer.ID
+}
+
+func (p *Policy) Enabled() bool {
+	return p != nil && len(p.Wallets) > 0
+}
+
+func (p *Policy) isApprover(approver peer.ID) bool {
+	for _, allowed := range p.Approvers {
+		if allowed == approver {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Submit verifies the signed approval and stores it in the approval directory
+func (p *Policy) Submit(signed SignedApproval) error {
+	approval, approver, err := signed.Verify()
+	if err != nil {
+		return err
+	}
+
+	if !p.isApprover(approver) {
+		return errors.Errorf("%s is not a configured approver", approver)
+	}
+
+	content, err := json.Marshal(signed)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode approval")
+	}
+
+	err = os.MkdirAll(p.Dir, 0o700)
+	if err != nil {
+		return errors.Wrap(err, "failed to create approval directory")
+	}
+
+	hash := sha256.Sum256(signed.Signature)
+	name := hex.EncodeToString(hash[:8]) + ".json"
+	err = os.WriteFile(filepath.Join(p.Dir, name), content, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write approval")
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.approvals == nil {
+		p.approvals = make(map[string]*storedApproval)
+	}
+
+	p.approvals[name] = &storedApproval{approval: approval, approver: approver}
+	return nil
+}
+
+// load verifies the approval files that are not known yet and forgets the ones that were deleted
+func (p *Policy) load() error {
+	log := logging.Logger("cosign")
+	entries, err := os.ReadDir(p.Dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "failed to read approval directory")
+	}
+
+	if p.approvals == nil {
+		p.approvals = make(map[string]*storedApproval)
+	}
+
+	names := make(map[string]struct{})
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		names[entry.Name()] = struct{}{}
+		if _, ok := p.approvals[entry.Name()]; ok {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(p.Dir, entry.Name()))
+		if err != nil {
+			return errors.Wrap(err, "failed to read approval")
+		}
+
+		p.approvals[entry.Name()] = nil
+		signed := SignedApproval{}
+		err = json.Unmarshal(content, &signed)
+		if err != nil {
+			log.Warnw("ignoring malformed approval", "file", entry.Name(), "error", err)
+			continue
+		}
+
+		approval, approver, err := signed.Verify()
+		if err != nil {
+			log.Warnw("ignoring invalid approval", "file", entry.Name(), "error", err)
+			continue
+		}
+
+		p.approvals[entry.Name()] = &storedApproval{approval: approval, approver: approver}
+	}
+
+	for name := range p.approvals {
+		if _, ok := names[name]; !ok {
+			delete(p.approvals, name)
+		}
+	}
+
+	return nil
+}
+
+// remove deletes an approval that expired or was used
+func (p *Policy) remove(name string) {
+	delete(p.approvals, name)
+	err := os.Remove(filepath.Join(p.Dir, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logging.Logger("cosign").Warnw("failed to remove approval", "file", name, "error", err)
+	}
+}
+
+// Check returns an error unless the proposal is covered by approvals from enough distinct configured approvers.
+// Expired approvals are removed, and so are the proposal CID approvals that allowed the proposal.
+func (p *Policy) Check(proposal *filmarket.DealProposal, now time.Time) error {
+	proposalCid, err := proposal.Cid()
+	if err != nil {
+		return errors.Wrap(err, "failed to compute proposal CID")
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	err = p.load()
+	if err != nil {
+		return err
+	}
+
+	approvers := make(map[peer.ID]struct{})
+	var used []string
+	for name, stored := range p.approvals {
+		if stored == nil {
+			continue
+		}
+
+		if !stored.approval.Expiry.IsZero() && now.After(stored.approval.Expiry) {
+			p.remove(name)
+			continue
+		}
+
+		if p.isApprover(stored.approver) && stored.approval.Matches(proposal, proposalCid, now) {
+			approvers[stored.approver] = struct{}{}
+			if stored.approval.ProposalCID != "" {
+				used = append(used, name)
+			}
+		}
+	}
+
+	minApprovals := p.MinApprovals
+	if minApprovals < 1 {
+		minApprovals = 1
+	}
+
+	if len(approvers) < minApprovals {
+		return errors.Errorf("proposal %s has %d of %d required co-signed approvals", proposalCid, len(approvers), minApprovals)
+	}
+
+	for _, name := range used {
+		p.remove(name)
+	}
+
+	return nil
+}
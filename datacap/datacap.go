@@ -0,0 +1,232 @@
+// Package datacap accounts for the verified piece size signed for each client wallet,
+// and limits it with budgets over rolling windows.
+package datacap
+
+import (
+	"fmt"
+	"github.com/docker/go-units"
+	"github.com/filecoin-project/go-address"
+	"github.com/pkg/errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope selects what a budget is kept for within a wallet
+type Scope string
+
+const (
+	// ScopeWallet limits the datacap signed for the wallet
+	ScopeWallet Scope = "wallet"
+	// ScopeRequester limits the datacap signed for the wallet by each requester
+	ScopeRequester Scope = "requester"
+	// ScopeProvider limits the datacap signed for the wallet to each provider
+	ScopeProvider Scope = "provider"
+)
+
+// Budget limits the verified piece size signed for a wallet within a rolling window
+type Budget struct {
+	// Wallet is the client wallet of the budget, or address.Undef for every wallet
+	Wallet address.Address
+	Scope  Scope
+	Window time.Duration
+	Limit  uint64
+}
+
+func (b Budget) String() string {
+	wallet := "*"
+	if b.Wallet != address.Undef {
+		wallet = b.Wallet.String()
+	}
+
+	return fmt.Sprintf("%s:%s:%s:%d", wallet, b.Scope, b.Window, b.Limit)
+}
+
+// ParseBudget decodes a budget given as <wallet address or *>:<wallet|requester|provider>:<window>:<limit>,
+// such as f1abc:requester:24h:100TiB. The limit is in bytes, or with a binary unit.
+func ParseBudget(value string) (Budget, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 {
+		return Budget{}, errors.Errorf("budget %s must be given as <wallet or *>:<wallet|requester|provider>:<window>:<limit>", value)
+	}
+
+	budget := Budget{Scope: Scope(parts[1])}
+	if parts[0] != "*" {
+		wallet, err := address.NewFromString(parts[0])
+		if err != nil {
+			return Budget{}, errors.Wrapf(err, "failed to decode wallet %s", parts[0])
+		}
+		budget.Wallet = wallet
+	}
+
+	if budget.Scope != ScopeWallet && budget.Scope != ScopeRequester && budget.Scope != ScopeProvider {
+		return Budget{}, errors.Errorf("unknown budget scope %s", parts[1])
+	}
+
+	window, err := time.ParseDuration(parts[2])
+	if err != nil {
+		return Budget{}, errors.Wrapf(err, "failed to decode window %s", parts[2])
+	}
+	if window <= 0 {
+		return Budget{}, errors.Errorf("window %s must be positive", parts[2])
+	}
+	budget.Window = window
+
+	limit, err := units.RAMInBytes(parts[3])
+	if err != nil {
+		return Budget{}, errors.Wrapf(err, "failed to decode limit %s", parts[3])
+	}
+	if limit <= 0 {
+		return Budget{}, errors.Errorf("limit %s must be positive", parts[3])
+	}
+	budget.Limit = uint64(limit)
+
+	return budget, nil
+}
+
+// Entry is the verified piece size of a signed proposal
+type Entry struct {
+	Time      time.Time
+	Wallet    string
+	Requester string
+	Provider  string
+	Size      uint64
+}
+
+// Ledger keeps the entries signed within the longest window of the budgets
+type Ledger struct {
+	mu      sync.Mutex
+	entries []Entry
+	window  time.Duration
+}
+
+// NewLedger creates a ledger keeping all entries until Keep is called
+func NewLedger() *Ledger {
+	return &Ledger{}
+}
+
+// Keep forgets the entries older than the window, now and whenever entries are added
+func (l *Ledger) Keep(window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.window = window
+	l.prune()
+}
+
+func (l *Ledger) prune() {
+	if l.window == 0 {
+		return
+	}
+
+	cutoff := time.Now().Add(-l.window)
+	kept := l.entries[:0]
+	for _, e := range l.entries {
+		if !e.Time.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	l.entries = kept
+}
+
+// Add adds the entry, and forgets the entries that left the window
+func (l *Ledger) Add(entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	l.prune()
+}
+
+// Remove removes an entry added for a proposal that ended up not signed
+func (l *Ledger) Remove(entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, e := range l.entries {
+		if e == entry {
+			l.entries = append(l.entries[:i], l.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Used returns the size signed for the entry in the scope of the budget since the start of the window.
+// The wallets are all the addresses of the entry wallet.
+func (l *Ledger) Used(budget Budget, wallets map[string]bool, entry Entry, now time.Time) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used(budget, wallets, entry, now)
+}
+
+func (l *Ledger) used(budget Budget, wallets map[string]bool, entry Entry, now time.Time) uint64 {
+	since := now.Add(-budget.Window)
+	var used uint64
+	for _, e := range l.entries {
+		if e.Time.Before(since) || !wallets[e.Wallet] {
+			continue
+		}
+
+		if budget.Scope == ScopeRequester && e.Requester != entry.Requester {
+			continue
+		}
+
+		if budget.Scope == ScopeProvider && e.Provider != entry.Provider {
+			continue
+		}
+
+		used += e.Size
+	}
+
+	return used
+}
+
+// Remaining returns the budget left for the entry, and whether the entry fits in it
+func (l *Ledger) Remaining(budget Budget, wallets map[string]bool, entry Entry, now time.Time) (uint64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.remaining(budget, wallets, entry, now)
+}
+
+func (l *Ledger) remaining(budget Budget, wallets map[string]bool, entry Entry, now time.Time) (uint64, bool) {
+	used := l.used(budget, wallets, entry, now)
+	if used+entry.Size > budget.Limit {
+		if used > budget.Limit {
+			return 0, false
+		}
+		return budget.Limit - used, false
+	}
+
+	return budget.Limit - used - entry.Size, true
+}
+
+// Usage is the budget left for an entry, and whether the entry fits in it
+type Usage struct {
+	Budget    Budget
+	Remaining uint64
+	Fits      bool
+}
+
+// Reserve checks the entry against the budgets of its wallets and adds it only if it fits in all of them,
+// in one step so that concurrent entries cannot overspend a budget.
+// It returns the usage of each budget of the wallets, and the function to remove the entry again if it was added.
+func (l *Ledger) Reserve(budgets []Budget, wallets map[string]bool, entry Entry) ([]Usage, func(), bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var usages []Usage
+	fits := true
+	for _, budget := range budgets {
+		if budget.Wallet != address.Undef && !wallets[budget.Wallet.String()] {
+			continue
+		}
+
+		remaining, ok := l.remaining(budget, wallets, entry, entry.Time)
+		usages = append(usages, Usage{Budget: budget, Remaining: remaining, Fits: ok})
+		fits = fits && ok
+	}
+
+	if !fits {
+		return usages, func() {}, false
+	}
+
+	l.entries = append(l.entries, entry)
+	l.prune()
+	return usages, func() { l.Remove(entry) }, true
+}
@@ -0,0 +1,94 @@
+package datacap
+
+import (
+	"github.com/filecoin-project/go-address"
+	"testing"
+	"time"
+)
+
+func TestParseBudget(t *testing.T) {
+	budget, err := ParseBudget("*:requester:24h:1GiB")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if budget.Wallet != address.Undef || budget.Scope != ScopeRequester || budget.Window != 24*time.Hour || budget.Limit != 1<<30 {
+		t.Fatalf("unexpected budget: %v", budget)
+	}
+
+	for _, value := range []string{"*:client:24h:1GiB", "*:wallet:0s:1GiB", "*:wallet:1h", "f0abc:wallet:1h:1GiB"} {
+		_, err = ParseBudget(value)
+		if err == nil {
+			t.Fatalf("expected an error for %s", value)
+		}
+	}
+}
+
+func TestLedger(t *testing.T) {
+	now := time.Now()
+	ledger := NewLedger()
+	ledger.Keep(24 * time.Hour)
+	ledger.Add(Entry{Time: now.Add(-25 * time.Hour), Wallet: "f01000", Requester: "a", Provider: "f01", Size: 100})
+	ledger.Add(Entry{Time: now.Add(-2 * time.Hour), Wallet: "f01000", Requester: "a", Provider: "f01", Size: 100})
+	ledger.Add(Entry{Time: now.Add(-time.Hour), Wallet: "f1abc", Requester: "b", Provider: "f02", Size: 200})
+	ledger.Add(Entry{Time: now.Add(-time.Hour), Wallet: "f01001", Requester: "a", Provider: "f01", Size: 400})
+
+	wallets := map[string]bool{"f01000": true, "f1abc": true}
+	entry := Entry{Time: now, Wallet: "f01000", Requester: "a", Provider: "f02", Size: 50}
+
+	remaining, ok := ledger.Remaining(Budget{Scope: ScopeWallet, Window: 24 * time.Hour, Limit: 350}, wallets, entry, now)
+	if !ok || remaining != 0 {
+		t.Fatalf("unexpected wallet budget: %d %v", remaining, ok)
+	}
+
+	remaining, ok = ledger.Remaining(Budget{Scope: ScopeWallet, Window: 90 * time.Minute, Limit: 220}, wallets, entry, now)
+	if ok || remaining != 20 {
+		t.Fatalf("unexpected wallet budget: %d %v", remaining, ok)
+	}
+
+	remaining, ok = ledger.Remaining(Budget{Scope: ScopeRequester, Window: 24 * time.Hour, Limit: 200}, wallets, entry, now)
+	if !ok || remaining != 50 {
+		t.Fatalf("unexpected requester budget: %d %v", remaining, ok)
+	}
+
+	remaining, ok = ledger.Remaining(Budget{Scope: ScopeProvider, Window: 24 * time.Hour, Limit: 200}, wallets, entry, now)
+	if ok || remaining != 0 {
+		t.Fatalf("unexpected provider budget: %d %v", remaining, ok)
+	}
+
+	ledger.Remove(Entry{Time: now.Add(-time.Hour), Wallet: "f1abc", Requester: "b", Provider: "f02", Size: 200})
+	remaining, ok = ledger.Remaining(Budget{Scope: ScopeProvider, Window: 24 * time.Hour, Limit: 200}, wallets, entry, now)
+	if !ok || remaining != 150 {
+		t.Fatalf("unexpected provider budget: %d %v", remaining, ok)
+	}
+}
+
+func TestLedgerReserve(t *testing.T) {
+	ledger := NewLedger()
+	wallets := map[string]bool{"f01000": true}
+	budgets := []Budget{
+		{Scope: ScopeWallet, Window: time.Hour, Limit: 300},
+		{Scope: ScopeRequester, Window: time.Hour, Limit: 100},
+		{Wallet: address.TestAddress, Scope: ScopeWallet, Window: time.Hour, Limit: 1},
+	}
+	entry := Entry{Time: time.Now(), Wallet: "f01000", Requester: "a", Provider: "f01", Size: 100}
+
+	usages, release, ok := ledger.Reserve(budgets, wallets, entry)
+	if !ok || len(usages) != 2 || usages[0].Remaining != 200 || usages[1].Remaining != 0 {
+		t.Fatalf("unexpected reservation: %v %v", usages, ok)
+	}
+
+	usages, _, ok = ledger.Reserve(budgets, wallets, entry)
+	if ok || usages[1].Fits || !usages[0].Fits {
+		t.Fatalf("expected the requester budget to be exceeded: %v", usages)
+	}
+
+	if ledger.Used(budgets[0], wallets, entry, time.Now()) != 100 {
+		t.Fatalf("expected the refused entry to not be added")
+	}
+
+	release()
+	_, _, ok = ledger.Reserve(budgets, wallets, entry)
+	if !ok {
+		t.Fatalf("expected released budget to be reserved again")
+	}
+}
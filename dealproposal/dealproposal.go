@@ -0,0 +1,196 @@
+// Package dealproposal decodes deal proposals with the DealProposal type of the market actor version they are made for,
+// and converts them to the v9 DealProposal the signer works with.
+package dealproposal
+
+import (
+	"bytes"
+	"github.com/filecoin-project/go-state-types/actors"
+	v10market "github.com/filecoin-project/go-state-types/builtin/v10/market"
+	v11market "github.com/filecoin-project/go-state-types/builtin/v11/market"
+	v8market "github.com/filecoin-project/go-state-types/builtin/v8/market"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
+)
+
+// Latest is the market actor version proposals are decoded with if no network version is given
+const Latest = actors.Version11
+
+// Versions are the supported market actor versions
+var Versions = []actors.Version{actors.Version8, actors.Version9, actors.Version10, actors.Version11}
+
+// Version returns the market actor version of the network version, or Latest if the network version is not set
+func Version(nv network.Version) (actors.Version, error) {
+	if nv == 0 {
+		return Latest, nil
+	}
+
+	version, err := actors.VersionForNetwork(nv)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get the actors version of network version %d", nv)
+	}
+
+	for _, supported := range Versions {
+		if supported == version {
+			return version, nil
+		}
+	}
+
+	return 0, errors.Errorf("actors version %d of network version %d is not supported", version, nv)
+}
+
+type label interface {
+	IsString() bool
+	ToString() (string, error)
+	ToBytes() ([]byte, error)
+}
+
+// labelBytes returns the content of the label and whether it is a string
+func labelBytes(l label) ([]byte, bool, error) {
+	if l.IsString() {
+		s, err := l.ToString()
+		return []byte(s), true, err
+	}
+
+	b, err := l.ToBytes()
+	return b, false, err
+}
+
+// convertLabel converts a label to the label type of another actor version
+func convertLabel[L any](l label, fromString func(string) (L, error), fromBytes func([]byte) (L, error)) (L, error) {
+	content, isString, err := labelBytes(l)
+	if err != nil {
+		var empty L
+		return empty, errors.Wrap(err, "failed to read label")
+	}
+
+	if isString {
+		return fromString(string(content))
+	}
+
+	return fromBytes(content)
+}
+
+type proposal interface {
+	cbg.CBORMarshaler
+	cbg.CBORUnmarshaler
+}
+
+// fromV9 converts the proposal to the DealProposal of the actor version
+func fromV9(p *filmarket.DealProposal, version actors.Version) (proposal, error) {
+	switch version {
+	case actors.Version8:
+		l, err := convertLabel(p.Label, v8market.NewLabelFromString, v8market.NewLabelFromBytes)
+		return &v8market.DealProposal{
+			PieceCID: p.PieceCID, PieceSize: p.PieceSize, VerifiedDeal: p.VerifiedDeal, Client: p.Client, Provider: p.Provider,
+			Label: l, StartEpoch: p.StartEpoch, EndEpoch: p.EndEpoch, StoragePricePerEpoch: p.StoragePricePerEpoch,
+			ProviderCollateral: p.ProviderCollateral, ClientCollateral: p.ClientCollateral,
+		}, err
+	case actors.Version9:
+		return p, nil
+	case actors.Version10:
+		l, err := convertLabel(p.Label, v10market.NewLabelFromString, v10market.NewLabelFromBytes)
+		return &v10market.DealProposal{
+			PieceCID: p.PieceCID, PieceSize: p.PieceSize, VerifiedDeal: p.VerifiedDeal, Client: p.Client, Provider: p.Provider,
+			Label: l, StartEpoch: p.StartEpoch, EndEpoch: p.EndEpoch, StoragePricePerEpoch: p.StoragePricePerEpoch,
+			ProviderCollateral: p.ProviderCollateral, ClientCollateral: p.ClientCollateral,
+		}, err
+	case actors.Version11:
+		l, err := convertLabel(p.Label, v11market.NewLabelFromString, v11market.NewLabelFromBytes)
+		return &v11market.DealProposal{
+			PieceCID: p.PieceCID, PieceSize: p.PieceSize, VerifiedDeal: p.VerifiedDeal, Client: p.Client, Provider: p.Provider,
+			Label: l, StartEpoch: p.StartEpoch, EndEpoch: p.EndEpoch, StoragePricePerEpoch: p.StoragePricePerEpoch,
+			ProviderCollateral: p.ProviderCollateral, ClientCollateral: p.ClientCollateral,
+		}, err
+	default:
+		return nil, errors.Errorf("actors version %d is not supported", version)
+	}
+}
+
+// toV9 converts the DealProposal of an actor version to the v9 DealProposal
+func toV9(p proposal) (*filmarket.DealProposal, error) {
+	converted := new(filmarket.DealProposal)
+	var l label
+	switch p := p.(type) {
+	case *v8market.DealProposal:
+		*converted = filmarket.DealProposal{
+			PieceCID: p.PieceCID, PieceSize: p.PieceSize, VerifiedDeal: p.VerifiedDeal, Client: p.Client, Provider: p.Provider,
+			StartEpoch: p.StartEpoch, EndEpoch: p.EndEpoch, StoragePricePerEpoch: p.StoragePricePerEpoch,
+			ProviderCollateral: p.ProviderCollateral, ClientCollateral: p.ClientCollateral,
+		}
+		l = p.Label
+	case *filmarket.DealProposal:
+		return p, nil
+	case *v10market.DealProposal:
+		*converted = filmarket.DealProposal{
+			PieceCID: p.PieceCID, PieceSize: p.PieceSize, VerifiedDeal: p.VerifiedDeal, Client: p.Client, Provider: p.Provider,
+			StartEpoch: p.StartEpoch, EndEpoch: p.EndEpoch, StoragePricePerEpoch: p.StoragePricePerEpoch,
+			ProviderCollateral: p.ProviderCollateral, ClientCollateral: p.ClientCollateral,
+		}
+		l = p.Label
+	case *v11market.DealProposal:
+		*converted = filmarket.DealProposal{
+			PieceCID: p.PieceCID, PieceSize: p.PieceSize, VerifiedDeal: p.VerifiedDeal, Client: p.Client, Provider: p.Provider,
+			StartEpoch: p.StartEpoch, EndEpoch: p.EndEpoch, StoragePricePerEpoch: p.StoragePricePerEpoch,
+			ProviderCollateral: p.ProviderCollateral, ClientCollateral: p.ClientCollateral,
+		}
+		l = p.Label
+	default:
+		return nil, errors.Errorf("unsupported proposal type %T", p)
+	}
+
+	var err error
+	converted.Label, err = convertLabel(l, filmarket.NewLabelFromString, filmarket.NewLabelFromBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return converted, nil
+}
+
+func newProposal(version actors.Version) (proposal, error) {
+	switch version {
+	case actors.Version8:
+		return new(v8market.DealProposal), nil
+	case actors.Version9:
+		return new(filmarket.DealProposal), nil
+	case actors.Version10:
+		return new(v10market.DealProposal), nil
+	case actors.Version11:
+		return new(v11market.DealProposal), nil
+	default:
+		return nil, errors.Errorf("actors version %d is not supported", version)
+	}
+}
+
+// Decode decodes the proposal bytes with the DealProposal of the actor version
+func Decode(data []byte, version actors.Version) (*filmarket.DealProposal, error) {
+	p, err := newProposal(version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.UnmarshalCBOR(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode v%d deal proposal", version)
+	}
+
+	return toV9(p)
+}
+
+// Marshal encodes the proposal with the DealProposal of the actor version
+func Marshal(p *filmarket.DealProposal, version actors.Version) ([]byte, error) {
+	versioned, err := fromV9(p, version)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	err = versioned.MarshalCBOR(buf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode v%d deal proposal", version)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,85 @@
+package dealproposal
+
+import (
+	"bytes"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/big"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/ipfs/go-cid"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	version, err := Version(0)
+	if err != nil || version != Latest {
+		t.Fatalf("unexpected version %d: %v", version, err)
+	}
+
+	version, err = Version(network.Version17)
+	if err != nil || version != actors.Version9 {
+		t.Fatalf("unexpected version %d: %v", version, err)
+	}
+
+	_, err = Version(network.Version15)
+	if err == nil {
+		t.Fatal("expected an error for actors v7")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	stringLabel, err := filmarket.NewLabelFromString("label")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	bytesLabel, err := filmarket.NewLabelFromBytes([]byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	for _, label := range []filmarket.DealLabel{stringLabel, bytesLabel} {
+		proposal := &filmarket.DealProposal{
+			PieceCID:             cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+			PieceSize:            256,
+			VerifiedDeal:         true,
+			Client:               address.TestAddress,
+			Provider:             address.TestAddress2,
+			Label:                label,
+			StartEpoch:           10,
+			EndEpoch:             20,
+			StoragePricePerEpoch: big.Zero(),
+			ProviderCollateral:   big.NewInt(1),
+			ClientCollateral:     big.Zero(),
+		}
+		expected := new(bytes.Buffer)
+		err = proposal.MarshalCBOR(expected)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		for _, version := range Versions {
+			data, err := Marshal(proposal, version)
+			if err != nil {
+				t.Fatalf("err is not null: %v", err)
+			}
+			if !bytes.Equal(data, expected.Bytes()) {
+				t.Fatalf("v%d encoding differs", version)
+			}
+
+			decoded, err := Decode(data, version)
+			if err != nil {
+				t.Fatalf("err is not null: %v", err)
+			}
+			if !decoded.Label.Equals(label) || decoded.EndEpoch != 20 || !decoded.ProviderCollateral.Equals(big.NewInt(1)) {
+				t.Fatalf("v%d proposal does not round-trip: %v", version, decoded)
+			}
+		}
+	}
+
+	_, err = Decode([]byte{0x80}, actors.Version7)
+	if err == nil {
+		t.Fatal("expected an error for actors v7")
+	}
+}
@@ -0,0 +1,61 @@
+// Package epochwindow limits the start and end epochs of the deal proposals the signer signs
+package epochwindow
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/pkg/errors"
+)
+
+// Window limits the epochs of a proposal relative to the current epoch. Zero fields disable their limit.
+type Window struct {
+	// MinLead and MaxLead bound the number of epochs between the current epoch and StartEpoch
+	MinLead abi.ChainEpoch
+	MaxLead abi.ChainEpoch
+	// MinDuration and MaxDuration bound the number of epochs between StartEpoch and EndEpoch
+	MinDuration abi.ChainEpoch
+	MaxDuration abi.ChainEpoch
+	// MaxEndLead bounds the number of epochs between the current epoch and EndEpoch,
+	// so that the deal ends before the latest expiration of a sector sealed for it
+	MaxEndLead abi.ChainEpoch
+}
+
+func (w Window) Enabled() bool {
+	return w.MinLead > 0 || w.MaxLead > 0 || w.MinDuration > 0 || w.MaxDuration > 0 || w.MaxEndLead > 0
+}
+
+// Check returns why the proposal is outside the window at the current epoch, or nil if it is inside.
+// Proposals starting at or before the current epoch are always stale.
+func (w Window) Check(proposal *filmarket.DealProposal, current abi.ChainEpoch) error {
+	lead := proposal.StartEpoch - current
+	if lead <= 0 {
+		return errors.Errorf("start epoch %d is not after the current epoch %d", proposal.StartEpoch, current)
+	}
+
+	if w.MinLead > 0 && lead < w.MinLead {
+		return errors.Errorf("start epoch %d is less than %d epochs after the current epoch %d", proposal.StartEpoch, w.MinLead, current)
+	}
+
+	if w.MaxLead > 0 && lead > w.MaxLead {
+		return errors.Errorf("start epoch %d is more than %d epochs after the current epoch %d", proposal.StartEpoch, w.MaxLead, current)
+	}
+
+	duration := proposal.EndEpoch - proposal.StartEpoch
+	if duration <= 0 {
+		return errors.Errorf("end epoch %d is not after the start epoch %d", proposal.EndEpoch, proposal.StartEpoch)
+	}
+
+	if w.MinDuration > 0 && duration < w.MinDuration {
+		return errors.Errorf("duration of %d epochs is less than %d epochs", duration, w.MinDuration)
+	}
+
+	if w.MaxDuration > 0 && duration > w.MaxDuration {
+		return errors.Errorf("duration of %d epochs is more than %d epochs", duration, w.MaxDuration)
+	}
+
+	if w.MaxEndLead > 0 && proposal.EndEpoch-current > w.MaxEndLead {
+		return errors.Errorf("end epoch %d is more than %d epochs after the current epoch %d", proposal.EndEpoch, w.MaxEndLead, current)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package epochwindow
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	window := Window{MinLead: 10, MaxLead: 100, MinDuration: 1000, MaxDuration: 2000, MaxEndLead: 2050}
+	for _, tc := range []struct {
+		start, end int64
+		ok         bool
+	}{
+		{start: 1050, end: 2050, ok: true},
+		{start: 1000, end: 2000, ok: false},
+		{start: 900, end: 2000, ok: false},
+		{start: 1005, end: 2005, ok: false},
+		{start: 1101, end: 2101, ok: false},
+		{start: 1050, end: 2049, ok: false},
+		{start: 1050, end: 3051, ok: false},
+		{start: 1100, end: 3051, ok: false},
+		{start: 1050, end: 1050, ok: false},
+	} {
+		proposal := &filmarket.DealProposal{StartEpoch: abi.ChainEpoch(tc.start), EndEpoch: abi.ChainEpoch(tc.end)}
+		err := window.Check(proposal, 1000)
+		if (err == nil) != tc.ok {
+			t.Fatalf("unexpected result for %d-%d: %v", tc.start, tc.end, err)
+		}
+	}
+
+	if (Window{}).Enabled() || !window.Enabled() {
+		t.Fatal("unexpected enabled state")
+	}
+}
@@ -0,0 +1,104 @@
+package inspect
+
+import (
+	"encoding/binary"
+	"github.com/pkg/errors"
+)
+
+const (
+	majorArray = 4
+	majorMap   = 5
+	majorTag   = 6
+	majorOther = 7
+)
+
+// readHeader parses the CBOR header at the start of data, returning the major type, its argument and the header length
+func readHeader(data []byte) (byte, uint64, int, error) {
+	if len(data) == 0 {
+		return 0, 0, 0, errors.New("unexpected end of data")
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info), 1, nil
+	case info == 24 && len(data) >= 2:
+		return major, uint64(data[1]), 2, nil
+	case info == 25 && len(data) >= 3:
+		return major, uint64(binary.BigEndian.Uint16(data[1:])), 3, nil
+	case info == 26 && len(data) >= 5:
+		return major, uint64(binary.BigEndian.Uint32(data[1:])), 5, nil
+	case info == 27 && len(data) >= 9:
+		return major, binary.BigEndian.Uint64(data[1:]), 9, nil
+	case info == 31:
+		return 0, 0, 0, errors.New("indefinite length items are not supported")
+	default:
+		return 0, 0, 0, errors.New("invalid or truncated header")
+	}
+}
+
+// itemLength returns the length of the encoded CBOR item at the start of data
+func itemLength(data []byte) (int, error) {
+	major, argument, length, err := readHeader(data)
+	if err != nil {
+		return 0, err
+	}
+
+	items := uint64(0)
+	switch major {
+	case 2, 3:
+		if argument > uint64(len(data)-length) {
+			return 0, errors.New("unexpected end of data")
+		}
+		return length + int(argument), nil
+	case majorArray:
+		items = argument
+	case majorMap:
+		items = argument * 2
+	case majorTag:
+		items = 1
+	case majorOther:
+		return length, nil
+	}
+
+	for i := uint64(0); i < items; i++ {
+		itemLen, err := itemLength(data[length:])
+		if err != nil {
+			return 0, err
+		}
+		length += itemLen
+	}
+
+	return length, nil
+}
+
+// splitArray splits an encoded CBOR array into its header and the encoded items
+func splitArray(data []byte) ([]byte, [][]byte, error) {
+	major, argument, length, err := readHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if major != majorArray {
+		return nil, nil, errors.New("not an array")
+	}
+
+	if argument > uint64(len(data)) {
+		return nil, nil, errors.New("unexpected end of data")
+	}
+
+	header := data[:length]
+	items := make([][]byte, 0, argument)
+	rest := data[length:]
+	for i := uint64(0); i < argument; i++ {
+		itemLen, err := itemLength(rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, rest[:itemLen])
+		rest = rest[itemLen:]
+	}
+
+	return header, items, nil
+}
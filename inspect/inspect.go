@@ -0,0 +1,294 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	"github.com/pkg/errors"
+	cbg "github.com/whyrusleeping/cbor-gen"
+)
+
+// Value is a wire type that can be decoded and re-encoded
+type Value interface {
+	cbg.CBORMarshaler
+	cbg.CBORUnmarshaler
+}
+
+// Type describes a wire type the inspector knows how to decode
+type Type struct {
+	Name string
+	New  func() Value
+	// Fields are the names of the entries of tuple encoded types, used for field level diffs
+	Fields []string
+	// Nested maps a field to the names of the entries of the tuple encoded type it holds
+	Nested map[string][]string
+}
+
+// DealProposalFields are the tuple entries of a market DealProposal, in encoding order
+var DealProposalFields = []string{
+	"PieceCID",
+	"PieceSize",
+	"VerifiedDeal",
+	"Client",
+	"Provider",
+	"Label",
+	"StartEpoch",
+	"EndEpoch",
+	"StoragePricePerEpoch",
+	"ProviderCollateral",
+	"ClientCollateral",
+}
+
+// Types are the known wire types, in the order they are tried when the type is not given.
+// Tuple encoded types come first, as map encoded types ignore unknown fields and decode too leniently.
+var Types = []Type{
+	{
+		Name:   "DealProposal",
+		New:    func() Value { return new(filmarket.DealProposal) },
+		Fields: DealProposalFields,
+	},
+	{
+		Name:   "ClientDealProposal",
+		New:    func() Value { return new(filmarket.ClientDealProposal) },
+		Fields: []string{"Proposal", "ClientSignature"},
+		Nested: map[string][]string{"Proposal": DealProposalFields},
+	},
+	{Name: "PingResponse", New: func() Value { return new(model.PingResponse) }},
+	{Name: "SignerInfo", New: func() Value { return new(model.SignerInfo) }},
+	{Name: "SignerResponse", New: func() Value { return new(model.SignerResponse) }},
+	{Name: "SignRequest", New: func() Value { return new(model.SignRequest) }},
+}
+
+// FieldDiff is a field whose encoding differs between the original and the re-marshalled bytes
+type FieldDiff struct {
+	Field string `json:"field"`
+	// Offset is the position in the original bytes of the first byte that differs
+	Offset       int      `json:"offset"`
+	Original     HexBytes `json:"original"`
+	Remarshalled HexBytes `json:"remarshalled"`
+}
+
+// HexBytes are bytes shown as hex in JSON
+type HexBytes []byte
+
+func (b HexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+// Result is a decoded wire value
+type Result struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+	// ProposalCID is the CID of the proposal as the signer computes it, from the re-marshalled bytes
+	ProposalCID string `json:"proposalCid,omitempty"`
+	// OriginalCID is the CID of the original bytes, set if they differ from the re-marshalled bytes
+	OriginalCID string      `json:"originalCid,omitempty"`
+	Remarshal   bool        `json:"remarshalMatches"`
+	Diff        []FieldDiff `json:"diff,omitempty"`
+}
+
+// LookupType returns the known type with the name
+func LookupType(name string) (Type, bool) {
+	for _, typ := range Types {
+		if typ.Name == name {
+			return typ, true
+		}
+	}
+
+	return Type{}, false
+}
+
+// Decode decodes the bytes as the type with the name, or tries all known types if the name is empty
+func Decode(data []byte, name string) (*Result, error) {
+	if name != "" {
+		typ, ok := LookupType(name)
+		if !ok {
+			return nil, errors.Errorf("unknown type %s", name)
+		}
+
+		return decodeAs(data, typ)
+	}
+
+	var lenient *Result
+	for _, typ := range Types {
+		result, err := decodeAs(data, typ)
+		if err != nil {
+			continue
+		}
+
+		// Tuple types only decode from the right number of entries, but map types decode from any map
+		if typ.Fields != nil || result.Remarshal {
+			return result, nil
+		}
+
+		if lenient == nil {
+			lenient = result
+		}
+	}
+
+	if lenient != nil {
+		return lenient, nil
+	}
+
+	return nil, errors.New("failed to decode the bytes as any known type")
+}
+
+func decodeAs(data []byte, typ Type) (*Result, error) {
+	value := typ.New()
+	reader := bytes.NewReader(data)
+	err := value.UnmarshalCBOR(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", typ.Name)
+	}
+
+	if reader.Len() > 0 {
+		return nil, errors.Errorf("failed to decode %s: %d trailing bytes", typ.Name, reader.Len())
+	}
+
+	remarshalled, err := cborutil.Dump(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to re-marshal %s", typ.Name)
+	}
+
+	result := &Result{
+		Type:      typ.Name,
+		Value:     value,
+		Remarshal: bytes.Equal(data, remarshalled),
+	}
+
+	var proposal *filmarket.DealProposal
+	switch value := value.(type) {
+	case *filmarket.DealProposal:
+		proposal = value
+	case *filmarket.ClientDealProposal:
+		proposal = &value.Proposal
+	}
+
+	if proposal != nil {
+		proposalCid, err := proposal.Cid()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compute proposal CID")
+		}
+		result.ProposalCID = proposalCid.String()
+	}
+
+	if !result.Remarshal {
+		originalCid, err := cid.V1Builder{Codec: cid.DagCBOR, MhType: multihash.SHA2_256}.Sum(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compute CID of the original bytes")
+		}
+		result.OriginalCID = originalCid.String()
+
+		result.Diff, err = diff("", 0, typ.Fields, typ.Nested, data, remarshalled)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// DiffProposal compares the original proposal bytes with the proposal re-marshalled from them,
+// field by field. It returns no diffs if the proposal bytes are canonical.
+func DiffProposal(original []byte) ([]FieldDiff, error) {
+	proposal := new(filmarket.DealProposal)
+	err := proposal.UnmarshalCBOR(bytes.NewReader(original))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode proposal")
+	}
+
+	remarshalled, err := cborutil.Dump(proposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to re-marshal proposal")
+	}
+
+	if bytes.Equal(original, remarshalled) {
+		return nil, nil
+	}
+
+	return diff("", 0, DealProposalFields, nil, original, remarshalled)
+}
+
+// diff compares two encodings of a tuple encoded value field by field. The offset is the position of the value
+// in the outermost original bytes. Types without field names are compared as a whole.
+func diff(prefix string, offset int, fields []string, nested map[string][]string, original, remarshalled []byte) ([]FieldDiff, error) {
+	whole := []FieldDiff{newFieldDiff(prefix+"*", offset, original, remarshalled)}
+	if fields == nil {
+		return whole, nil
+	}
+
+	// The original bytes decoded, but may still use encodings the splitter does not handle
+	originalHeader, originalItems, err := splitArray(original)
+	if err != nil {
+		return whole, nil
+	}
+
+	remarshalledHeader, remarshalledItems, err := splitArray(remarshalled)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to split re-marshalled bytes")
+	}
+
+	if len(originalItems) != len(remarshalledItems) {
+		return whole, nil
+	}
+
+	var diffs []FieldDiff
+	if !bytes.Equal(originalHeader, remarshalledHeader) {
+		diffs = append(diffs, newFieldDiff(prefix+"<header>", offset, originalHeader, remarshalledHeader))
+	}
+
+	itemOffset := offset + len(originalHeader)
+	for i := range remarshalledItems {
+		if bytes.Equal(originalItems[i], remarshalledItems[i]) {
+			itemOffset += len(originalItems[i])
+			continue
+		}
+
+		field := prefix + fieldName(fields, i)
+		if nestedFields, ok := nested[fieldName(fields, i)]; ok {
+			nestedDiffs, err := diff(field+".", itemOffset, nestedFields, nil, originalItems[i], remarshalledItems[i])
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, nestedDiffs...)
+		} else {
+			diffs = append(diffs, newFieldDiff(field, itemOffset, originalItems[i], remarshalledItems[i]))
+		}
+
+		itemOffset += len(originalItems[i])
+	}
+
+	return diffs, nil
+}
+
+func newFieldDiff(field string, offset int, original, remarshalled []byte) FieldDiff {
+	return FieldDiff{
+		Field:        field,
+		Offset:       offset + firstDivergence(original, remarshalled),
+		Original:     original,
+		Remarshalled: remarshalled,
+	}
+}
+
+// firstDivergence returns the index of the first byte that differs
+func firstDivergence(a, b []byte) int {
+	for i := range a {
+		if i >= len(b) || a[i] != b[i] {
+			return i
+		}
+	}
+
+	return len(a)
+}
+
+func fieldName(fields []string, i int) string {
+	if i < len(fields) {
+		return fields[i]
+	}
+
+	return "<unknown>"
+}
@@ -0,0 +1,106 @@
+package inspect
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/filecoin-project/go-state-types/big"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/ipfs/go-cid"
+	"testing"
+)
+
+func testProposalBytes(t *testing.T) []byte {
+	t.Helper()
+	proposal := &filmarket.DealProposal{
+		PieceCID:             cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+		PieceSize:            256,
+		Client:               address.TestAddress,
+		Provider:             address.TestAddress2,
+		Label:                filmarket.EmptyDealLabel,
+		StoragePricePerEpoch: big.Zero(),
+		ProviderCollateral:   big.Zero(),
+		ClientCollateral:     big.Zero(),
+	}
+	proposalBytes, err := cborutil.Dump(proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	return proposalBytes
+}
+
+func TestDecode(t *testing.T) {
+	proposalBytes := testProposalBytes(t)
+	result, err := Decode(proposalBytes, "")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if result.Type != "DealProposal" || !result.Remarshal || result.ProposalCID == "" || len(result.Diff) != 0 {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	responseBytes, err := cborutil.Dump(&model.SignerResponse{Code: model.PendingApproval, Ticket: "ticket"})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	result, err = Decode(responseBytes, "")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	response, ok := result.Value.(*model.SignerResponse)
+	if result.Type != "SignerResponse" || !ok || response.Ticket != "ticket" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
+
+func TestDiffProposal(t *testing.T) {
+	proposalBytes := testProposalBytes(t)
+	header, items, err := splitArray(proposalBytes)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	// Encode the zero provider collateral with a zero magnitude byte instead of as empty bytes
+	nonCanonical := append([]byte{}, header...)
+	for i, item := range items {
+		if i == 9 {
+			item = []byte{0x42, 0x00, 0x00}
+		}
+		nonCanonical = append(nonCanonical, item...)
+	}
+
+	offset := len(header)
+	for _, item := range items[:9] {
+		offset += len(item)
+	}
+
+	diffs, err := DiffProposal(nonCanonical)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if len(diffs) != 1 || diffs[0].Field != "ProviderCollateral" || diffs[0].Offset != offset ||
+		hex.EncodeToString(diffs[0].Original) != "420000" || hex.EncodeToString(diffs[0].Remarshalled) != "40" {
+		t.Fatalf("unexpected diffs: %v", diffs)
+	}
+
+	result, err := Decode(nonCanonical, "DealProposal")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if result.Remarshal || result.OriginalCID == "" || result.OriginalCID == result.ProposalCID || len(result.Diff) != 1 {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	diffs, err = DiffProposal(proposalBytes)
+	if err != nil || diffs != nil || !bytes.Equal(proposalBytes, testProposalBytes(t)) {
+		t.Fatalf("expected no diffs for canonical proposal: %v %v", diffs, err)
+	}
+}
@@ -0,0 +1,120 @@
+package keystore
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/json"
+	"github.com/data-preservation-programs/filsigner-relayed/secret"
+	"github.com/filecoin-project/go-address"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// walletPrefix is the name prefix of wallet keys in a Lotus keystore
+const walletPrefix = "wallet-"
+
+// nameEncoding is the unpadded base32 encoding Lotus uses for key file names
+var nameEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Dir is a keystore directory in the Lotus layout, where each key is a JSON KeyInfo file named by
+// the base32 encoding of "wallet-<address>". Keys can be copied over from a Lotus repository.
+type Dir struct {
+	path string
+}
+
+// NewDir opens the keystore directory, creating it if it does not exist
+func NewDir(path string) (*Dir, error) {
+	err := os.MkdirAll(path, 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create keystore directory")
+	}
+
+	return &Dir{path: path}, nil
+}
+
+func (d *Dir) fileName(addr address.Address) string {
+	return filepath.Join(d.path, nameEncoding.EncodeToString([]byte(walletPrefix+addr.String())))
+}
+
+func (d *Dir) List(_ context.Context) ([]address.Address, error) {
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read keystore directory")
+	}
+
+	var addrs []address.Address
+	for _, entry := range entries {
+		name, err := nameEncoding.DecodeString(entry.Name())
+		if err != nil || !strings.HasPrefix(string(name), walletPrefix) {
+			continue
+		}
+
+		addr, err := address.NewFromString(strings.TrimPrefix(string(name), walletPrefix))
+		if err != nil {
+			continue
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].String() < addrs[j].String()
+	})
+	return addrs, nil
+}
+
+func (d *Dir) Sign(ctx context.Context, addr address.Address, message []byte, _ MsgMeta) (*filcrypto.Signature, error) {
+	key, err := d.Export(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return sign(key, message)
+}
+
+func (d *Dir) Export(_ context.Context, addr address.Address) (*KeyInfo, error) {
+	content, err := secret.ReadFile(d.fileName(addr))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read key")
+	}
+
+	key := new(KeyInfo)
+	err = json.Unmarshal(content, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode key")
+	}
+
+	return key, nil
+}
+
+func (d *Dir) Import(_ context.Context, key *KeyInfo) (address.Address, error) {
+	addr, err := Address(key)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	content, err := json.Marshal(key)
+	if err != nil {
+		return address.Undef, errors.Wrap(err, "failed to encode key")
+	}
+
+	tmpPath := d.fileName(addr) + ".tmp"
+	err = os.WriteFile(tmpPath, content, 0600)
+	if err != nil {
+		return address.Undef, errors.Wrap(err, "failed to write key")
+	}
+
+	err = os.Rename(tmpPath, d.fileName(addr))
+	if err != nil {
+		return address.Undef, errors.Wrap(err, "failed to store key")
+	}
+
+	return addr, nil
+}
@@ -0,0 +1,89 @@
+package keystore
+
+import (
+	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/secret"
+	"github.com/filecoin-project/go-address"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"sync"
+)
+
+// Files is a read only keystore of keys exported with `lotus wallet export`, read from secret files and
+// a directory of secret files such as a Docker or Kubernetes secret mount. The keys are read again on reload.
+type Files struct {
+	paths []string
+	dir   string
+
+	mu     sync.RWMutex
+	static *Static
+}
+
+// NewFiles reads the keys from the files, with one or more keys per line, and from every file in dir if it is set
+func NewFiles(paths []string, dir string) (*Files, error) {
+	files := &Files{paths: paths, dir: dir}
+	err := files.Reload(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// Reload reads the keys again and puts them in use
+func (f *Files) Reload(ctx context.Context) error {
+	_, commit, err := f.Prepare(ctx)
+	if err != nil {
+		return err
+	}
+
+	commit()
+	return nil
+}
+
+func (f *Files) Prepare(_ context.Context) (Keystore, func(), error) {
+	var exported []string
+	for _, path := range f.paths {
+		keys, err := secret.ReadList(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		exported = append(exported, keys...)
+	}
+
+	if f.dir != "" {
+		keys, err := secret.ReadDir(f.dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		exported = append(exported, keys...)
+	}
+
+	static, err := NewStatic(exported)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return static, func() {
+		f.mu.Lock()
+		f.static = static
+		f.mu.Unlock()
+	}, nil
+}
+
+func (f *Files) current() *Static {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.static
+}
+
+func (f *Files) List(ctx context.Context) ([]address.Address, error) {
+	return f.current().List(ctx)
+}
+
+func (f *Files) Sign(ctx context.Context, addr address.Address, message []byte, meta MsgMeta) (*filcrypto.Signature, error) {
+	return f.current().Sign(ctx, addr, message, meta)
+}
+
+func (f *Files) Export(ctx context.Context, addr address.Address) (*KeyInfo, error) {
+	return f.current().Export(ctx, addr)
+}
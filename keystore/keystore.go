@@ -0,0 +1,164 @@
+package keystore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	curve12381 "github.com/drand/kyber-bls12381"
+	"github.com/filecoin-project/go-address"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/jsign/go-filsigner/wallet"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/pkg/errors"
+)
+
+// KeyInfo is a wallet private key in the Lotus format
+type KeyInfo = wallet.KeyInfo
+
+var (
+	ErrKeyNotFound = errors.New("key not found")
+	ErrReadOnly    = errors.New("keystore is read only")
+)
+
+// MsgType tells remote wallets what kind of message they are asked to sign, as in the Lotus wallet API
+type MsgType string
+
+const (
+	MTUnknown           MsgType = "unknown"
+	MTChainMsg          MsgType = "message"
+	MTBlock             MsgType = "block"
+	MTDealProposal      MsgType = "dealproposal"
+	MTProviderDealState MsgType = "providerdealstate"
+)
+
+// MsgTypes are the message types known to the Lotus wallet API
+var MsgTypes = []MsgType{MTUnknown, MTChainMsg, MTBlock, MTDealProposal, MTProviderDealState}
+
+// MsgMeta describes the message to sign
+type MsgMeta struct {
+	Type MsgType
+	// Extra is additional data the wallet can use to check the message
+	Extra []byte
+}
+
+// Keystore holds the wallet keys the server signs with
+type Keystore interface {
+	// List returns the key addresses of the wallets in the keystore
+	List(ctx context.Context) ([]address.Address, error)
+	// Sign signs the message with the key of the address. It returns ErrKeyNotFound if the keystore does not hold the key.
+	Sign(ctx context.Context, addr address.Address, message []byte, meta MsgMeta) (*filcrypto.Signature, error)
+}
+
+// Importer is a keystore that new keys can be added to
+type Importer interface {
+	Import(ctx context.Context, key *KeyInfo) (address.Address, error)
+}
+
+// Exporter is a keystore that can hand out the private keys it holds
+type Exporter interface {
+	Export(ctx context.Context, addr address.Address) (*KeyInfo, error)
+}
+
+// Reloader is a keystore that reads its keys again when the server is reloaded
+type Reloader interface {
+	// Prepare reads the keys again and returns a keystore of the new keys, and the function that puts them in use.
+	// The current keys stay in use until it is called.
+	Prepare(ctx context.Context) (Keystore, func(), error)
+}
+
+// Encode encodes the key the way `lotus wallet export` does, as hex encoded JSON
+func Encode(key *KeyInfo) (string, error) {
+	content, err := json.Marshal(key)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode key")
+	}
+
+	return hex.EncodeToString(content), nil
+}
+
+// Decode decodes a key exported with `lotus wallet export`
+func Decode(exported string) (*KeyInfo, error) {
+	content, err := hex.DecodeString(exported)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode hex")
+	}
+
+	key := new(KeyInfo)
+	err = json.Unmarshal(content, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode key")
+	}
+
+	return key, nil
+}
+
+// Address returns the key address of the key
+func Address(key *KeyInfo) (address.Address, error) {
+	exported, err := Encode(key)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	addr, err := wallet.PublicKey(exported)
+	if err != nil {
+		return address.Undef, errors.Wrap(err, "failed to derive address")
+	}
+
+	return addr, nil
+}
+
+// Generate creates a new secp256k1 or bls key
+func Generate(keyType wallet.KeyType) (*KeyInfo, error) {
+	switch keyType {
+	case wallet.KTSecp256k1:
+		privateKey, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate secp256k1 key")
+		}
+
+		raw, err := privateKey.Raw()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get secp256k1 key bytes")
+		}
+
+		return &KeyInfo{Type: wallet.KTSecp256k1, PrivateKey: raw}, nil
+	case wallet.KTBLS:
+		seed := make([]byte, 32)
+		_, err := rand.Read(seed)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read random bytes")
+		}
+
+		// The scalar is reduced modulo the group order and serialized big-endian,
+		// while Filecoin stores bls private keys little-endian
+		scalar, err := curve12381.NewKyberScalar().SetBytes(seed).MarshalBinary()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode bls key")
+		}
+
+		privateKey := make([]byte, len(scalar))
+		for i := range scalar {
+			privateKey[i] = scalar[len(scalar)-1-i]
+		}
+
+		return &KeyInfo{Type: wallet.KTBLS, PrivateKey: privateKey}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type %s", keyType)
+	}
+}
+
+// sign signs the message with the private key
+func sign(key *KeyInfo, message []byte) (*filcrypto.Signature, error) {
+	exported, err := Encode(key)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := wallet.WalletSign(exported, message)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign")
+	}
+
+	return signature, nil
+}
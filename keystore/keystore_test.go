@@ -0,0 +1,150 @@
+package keystore
+
+import (
+	"context"
+	"github.com/filecoin-project/go-address"
+	"github.com/jsign/go-filsigner/wallet"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testWalletKey = "7b2254797065223a22736563703235366b31222c22507269766174654b6579223a2244485a65316e7146756c7142382b44345a6167566f4f6654566d366e6f45415076414431705051446167343d227d"
+
+func TestDir(t *testing.T) {
+	ctx := context.Background()
+	dir, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	for _, keyType := range []wallet.KeyType{wallet.KTSecp256k1, wallet.KTBLS} {
+		key, err := Generate(keyType)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		addr, err := dir.Import(ctx, key)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		signature, err := dir.Sign(ctx, addr, []byte("message"), MsgMeta{Type: MTUnknown})
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		signatureBytes, err := signature.MarshalBinary()
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		valid, err := wallet.WalletVerify(addr, []byte("message"), signatureBytes)
+		if err != nil || !valid {
+			t.Fatalf("expected %s signature to be valid: %v", keyType, err)
+		}
+	}
+
+	addrs, err := dir.List(ctx)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if len(addrs) != 2 {
+		t.Fatalf("unexpected addresses: %v", addrs)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	ctx := context.Background()
+	address.CurrentNetwork = address.Mainnet
+	static, err := NewStatic([]string{testWalletKey})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	dir, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	_, err = Multi{static}.Import(ctx, nil)
+	if err != ErrReadOnly {
+		t.Fatalf("expected static keystore to be read only: %v", err)
+	}
+
+	store := Multi{dir, static}
+	staticAddrs, err := static.List(ctx)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	exported, err := store.Export(ctx, staticAddrs[0])
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	encoded, err := Encode(exported)
+	if err != nil || encoded != testWalletKey {
+		t.Fatalf("unexpected exported key: %s %v", encoded, err)
+	}
+
+	key, err := Generate(wallet.KTSecp256k1)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	addr, err := store.Import(ctx, key)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	addrs, err := store.List(ctx)
+	if err != nil || len(addrs) != 2 || addrs[0] != addr {
+		t.Fatalf("unexpected addresses: %v %v", addrs, err)
+	}
+
+	_, err = store.Sign(ctx, staticAddrs[0], []byte("message"), MsgMeta{Type: MTUnknown})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	files, err := NewFiles(nil, dir)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	addrs, err := files.List(ctx)
+	if err != nil || len(addrs) != 0 {
+		t.Fatalf("unexpected addresses: %v %v", addrs, err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "wallet"), []byte(testWalletKey+"\n"), 0o600)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = files.Reload(ctx)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	addrs, err = files.List(ctx)
+	if err != nil || len(addrs) != 1 {
+		t.Fatalf("unexpected addresses: %v %v", addrs, err)
+	}
+
+	err = os.Chmod(filepath.Join(dir, "wallet"), 0o644)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = files.Reload(ctx)
+	if err == nil {
+		t.Fatalf("expected world-readable key file to be refused")
+	}
+}
@@ -0,0 +1,65 @@
+package keystore
+
+import (
+	"context"
+	"github.com/filecoin-project/go-address"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/pkg/errors"
+	"github.com/ybbus/jsonrpc/v3"
+)
+
+// Lotus delegates signing to the wallet of a Lotus node or a lotus-wallet daemon over JSON-RPC.
+// The private keys never leave the wallet, so keys can neither be imported nor exported.
+type Lotus struct {
+	client jsonrpc.RPCClient
+	// fullNode is set for the full node API, whose WalletSign does not take the message metadata
+	fullNode bool
+}
+
+// NewLotus creates a keystore backed by the Lotus wallet API at the endpoint, such as http://127.0.0.1:1234/rpc/v0
+// @param token the API token, which needs the sign permission
+// @param fullNode whether the endpoint is a Lotus full node rather than a lotus-wallet daemon
+func NewLotus(endpoint string, token string, fullNode bool) *Lotus {
+	opts := &jsonrpc.RPCClientOpts{}
+	if token != "" {
+		opts.CustomHeaders = map[string]string{"Authorization": "Bearer " + token}
+	}
+
+	return &Lotus{client: jsonrpc.NewClientWithOpts(endpoint, opts), fullNode: fullNode}
+}
+
+func (l *Lotus) List(ctx context.Context) ([]address.Address, error) {
+	var addrs []address.Address
+	// A single slice argument is sent as the params array as it is
+	err := l.client.CallFor(ctx, &addrs, "Filecoin.WalletList", []any{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to call Filecoin.WalletList")
+	}
+
+	return addrs, nil
+}
+
+func (l *Lotus) Sign(ctx context.Context, addr address.Address, message []byte, meta MsgMeta) (*filcrypto.Signature, error) {
+	var has bool
+	err := l.client.CallFor(ctx, &has, "Filecoin.WalletHas", []any{addr})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to call Filecoin.WalletHas")
+	}
+
+	if !has {
+		return nil, ErrKeyNotFound
+	}
+
+	params := []any{addr, message}
+	if !l.fullNode {
+		params = append(params, meta)
+	}
+
+	signature := new(filcrypto.Signature)
+	err = l.client.CallFor(ctx, signature, "Filecoin.WalletSign", params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to call Filecoin.WalletSign")
+	}
+
+	return signature, nil
+}
@@ -0,0 +1,106 @@
+package keystore
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/filecoin-project/go-address"
+	"github.com/jsign/go-filsigner/wallet"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newLotusStub serves the wallet methods of the Lotus JSON-RPC API for the key, recording the metadata of signatures
+func newLotusStub(t *testing.T, key string, metas *[]MsgMeta) *httptest.Server {
+	t.Helper()
+	addr, err := wallet.PublicKey(key)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var request struct {
+			ID     int
+			Method string
+			Params []json.RawMessage
+		}
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var result any
+		switch request.Method {
+		case "Filecoin.WalletList":
+			result = []address.Address{addr}
+		case "Filecoin.WalletHas":
+			var requested address.Address
+			_ = json.Unmarshal(request.Params[0], &requested)
+			result = requested == addr
+		case "Filecoin.WalletSign":
+			var message []byte
+			_ = json.Unmarshal(request.Params[1], &message)
+			meta := MsgMeta{}
+			if len(request.Params) > 2 {
+				_ = json.Unmarshal(request.Params[2], &meta)
+			}
+			*metas = append(*metas, meta)
+			result, _ = wallet.WalletSign(key, message)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": request.ID, "result": result})
+	}))
+}
+
+func TestLotus(t *testing.T) {
+	ctx := context.Background()
+	address.CurrentNetwork = address.Mainnet
+	var metas []MsgMeta
+	stub := newLotusStub(t, testWalletKey, &metas)
+	defer stub.Close()
+
+	lotus := NewLotus(stub.URL, "token", false)
+	addrs, err := lotus.List(ctx)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if len(addrs) != 1 {
+		t.Fatalf("unexpected addresses: %v", addrs)
+	}
+
+	signature, err := lotus.Sign(ctx, addrs[0], []byte("proposal"), MsgMeta{Type: MTDealProposal})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	signatureBytes, err := signature.MarshalBinary()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	valid, err := wallet.WalletVerify(addrs[0], []byte("proposal"), signatureBytes)
+	if err != nil || !valid {
+		t.Fatalf("expected signature to be valid: %v", err)
+	}
+
+	if len(metas) != 1 || metas[0].Type != MTDealProposal {
+		t.Fatalf("unexpected message metadata: %v", metas)
+	}
+
+	_, err = lotus.Sign(ctx, address.TestAddress, []byte("proposal"), MsgMeta{Type: MTDealProposal})
+	if err != ErrKeyNotFound {
+		t.Fatalf("expected key not found: %v", err)
+	}
+
+	_, err = NewLotus(stub.URL, "", false).List(ctx)
+	if err == nil {
+		t.Fatalf("expected request without token to fail")
+	}
+}
@@ -0,0 +1,101 @@
+package keystore
+
+import (
+	"context"
+	"github.com/filecoin-project/go-address"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/pkg/errors"
+)
+
+// Multi combines keystores. Keys are looked up in order, and imported into the first keystore that accepts them.
+type Multi []Keystore
+
+func (m Multi) List(ctx context.Context) ([]address.Address, error) {
+	seen := make(map[address.Address]struct{})
+	var addrs []address.Address
+	for _, store := range m {
+		listed, err := store.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, addr := range listed {
+			if _, ok := seen[addr]; ok {
+				continue
+			}
+			seen[addr] = struct{}{}
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs, nil
+}
+
+func (m Multi) Sign(ctx context.Context, addr address.Address, message []byte, meta MsgMeta) (*filcrypto.Signature, error) {
+	for _, store := range m {
+		signature, err := store.Sign(ctx, addr, message, meta)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+
+		return signature, err
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+func (m Multi) Export(ctx context.Context, addr address.Address) (*KeyInfo, error) {
+	for _, store := range m {
+		exporter, ok := store.(Exporter)
+		if !ok {
+			continue
+		}
+
+		key, err := exporter.Export(ctx, addr)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+
+		return key, err
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+func (m Multi) Import(ctx context.Context, key *KeyInfo) (address.Address, error) {
+	for _, store := range m {
+		importer, ok := store.(Importer)
+		if ok {
+			return importer.Import(ctx, key)
+		}
+	}
+
+	return address.Undef, ErrReadOnly
+}
+
+// Prepare reads the keys of the keystores that can reload them, and puts them all in use together
+func (m Multi) Prepare(ctx context.Context) (Keystore, func(), error) {
+	prepared := make(Multi, len(m))
+	var commits []func()
+	for i, store := range m {
+		prepared[i] = store
+		reloader, ok := store.(Reloader)
+		if !ok {
+			continue
+		}
+
+		reloaded, commit, err := reloader.Prepare(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		prepared[i] = reloaded
+		commits = append(commits, commit)
+	}
+
+	return prepared, func() {
+		for _, commit := range commits {
+			commit()
+		}
+	}, nil
+}
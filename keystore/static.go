@@ -0,0 +1,63 @@
+package keystore
+
+import (
+	"context"
+	"github.com/filecoin-project/go-address"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"sort"
+)
+
+// Static is a read only keystore of keys given on the command line or in the environment
+type Static struct {
+	keys map[address.Address]*KeyInfo
+}
+
+// NewStatic creates a keystore from keys exported with `lotus wallet export`
+func NewStatic(exported []string) (*Static, error) {
+	static := &Static{keys: make(map[address.Address]*KeyInfo)}
+	for _, value := range exported {
+		key, err := Decode(value)
+		if err != nil {
+			return nil, err
+		}
+
+		addr, err := Address(key)
+		if err != nil {
+			return nil, err
+		}
+
+		static.keys[addr] = key
+	}
+
+	return static, nil
+}
+
+func (s *Static) List(_ context.Context) ([]address.Address, error) {
+	addrs := make([]address.Address, 0, len(s.keys))
+	for addr := range s.keys {
+		addrs = append(addrs, addr)
+	}
+
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].String() < addrs[j].String()
+	})
+	return addrs, nil
+}
+
+func (s *Static) Sign(_ context.Context, addr address.Address, message []byte, _ MsgMeta) (*filcrypto.Signature, error) {
+	key, ok := s.keys[addr]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return sign(key, message)
+}
+
+func (s *Static) Export(_ context.Context, addr address.Address) (*KeyInfo, error) {
+	key, ok := s.keys[addr]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, nil
+}
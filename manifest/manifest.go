@@ -0,0 +1,231 @@
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// signaturePrefix separates manifest signatures from any other use of the operator key
+const signaturePrefix = "filsigner-piece-manifest:"
+
+// Entry is a piece the operators onboarded
+type Entry struct {
+	PieceCID   string `json:"pieceCid"`
+	PieceSize  uint64 `json:"pieceSize"`
+	PayloadCID string `json:"payloadCid,omitempty"`
+	Label      string `json:"label,omitempty"`
+}
+
+// Manifest is the list of pieces the signer signs proposals for
+type Manifest struct {
+	// Version is the hex SHA-256 of the manifest content
+	Version string
+	pieces  map[cid.Cid]Entry
+}
+
+// SignedManifest is the content of a manifest signed by an operator key.
+// The payload is kept as the exact bytes that were signed.
+type SignedManifest struct {
+	Payload   []byte `json:"payload"`
+	Signer    string `json:"signer"`
+	Signature []byte `json:"signature"`
+}
+
+// Parse decodes a manifest from a JSON array of entries, or from CSV lines of
+// <piece CID>,<piece size>[,<payload CID>[,<label>]] with an optional header
+func Parse(data []byte) (*Manifest, error) {
+	var entries []Entry
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		err := json.Unmarshal(trimmed, &entries)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode manifest")
+		}
+	} else {
+		var err error
+		entries, err = parseCSV(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	digest := sha256.Sum256(data)
+	manifest := &Manifest{
+		Version: hex.EncodeToString(digest[:]),
+		pieces:  make(map[cid.Cid]Entry, len(entries)),
+	}
+	for _, entry := range entries {
+		pieceCid, err := cid.Decode(entry.PieceCID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid piece CID %s", entry.PieceCID)
+		}
+
+		err = abi.PaddedPieceSize(entry.PieceSize).Validate()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid size of piece %s", entry.PieceCID)
+		}
+
+		if entry.PayloadCID != "" {
+			_, err = cid.Decode(entry.PayloadCID)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid payload CID of piece %s", entry.PieceCID)
+			}
+		}
+
+		existing, ok := manifest.pieces[pieceCid]
+		if ok && existing.PieceSize != entry.PieceSize {
+			return nil, errors.Errorf("piece %s is listed with sizes %d and %d", entry.PieceCID, existing.PieceSize, entry.PieceSize)
+		}
+
+		manifest.pieces[pieceCid] = entry
+	}
+
+	return manifest, nil
+}
+
+func parseCSV(data []byte) ([]Entry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var entries []Entry
+	for first := true; ; first = false {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read manifest")
+		}
+
+		if first && strings.EqualFold(fields[0], "pieceCid") {
+			continue
+		}
+
+		// Records with quoted line breaks span several lines, so the line is where the record starts
+		line, _ := reader.FieldPos(0)
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, errors.Errorf("line %d of the manifest has %d fields, expected <piece CID>,<piece size>[,<payload CID>[,<label>]]", line, len(fields))
+		}
+
+		size, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid piece size on line %d of the manifest", line)
+		}
+
+		entry := Entry{PieceCID: fields[0], PieceSize: size}
+		if len(fields) > 2 {
+			entry.PayloadCID = fields[2]
+		}
+		if len(fields) > 3 {
+			entry.Label = fields[3]
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// Len returns the number of pieces in the manifest
+func (m *Manifest) Len() int {
+	return len(m.pieces)
+}
+
+// Check returns an error unless the piece is listed in the manifest with the size
+func (m *Manifest) Check(pieceCid cid.Cid, size abi.PaddedPieceSize) error {
+	entry, ok := m.pieces[pieceCid]
+	if !ok {
+		return errors.Errorf("piece %s is not in the piece manifest %s", pieceCid, m.Version)
+	}
+
+	if entry.PieceSize != uint64(size) {
+		return errors.Errorf("piece %s has size %d in the piece manifest %s, not %d", pieceCid, entry.PieceSize, m.Version, size)
+	}
+
+	return nil
+}
+
+// Sign signs the manifest content with the operator private key
+func Sign(key crypto.PrivKey, content []byte) (*SignedManifest, error) {
+	_, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get signer ID")
+	}
+
+	signature, err := key.Sign(append([]byte(signaturePrefix), content...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign manifest")
+	}
+
+	return &SignedManifest{
+		Payload:   content,
+		Signer:    signer.String(),
+		Signature: signature,
+	}, nil
+}
+
+// Verify checks that the manifest is signed by one of the operators and returns it
+func (s SignedManifest) Verify(operators []peer.ID) (*Manifest, error) {
+	signer, err := peer.Decode(s.Signer)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid signer")
+	}
+
+	trusted := false
+	for _, operator := range operators {
+		if operator == signer {
+			trusted = true
+		}
+	}
+	if !trusted {
+		return nil, errors.Errorf("manifest is signed by %s, which is not an operator key", signer)
+	}
+
+	publicKey, err := signer.ExtractPublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot extract signer public key, use an ed25519 operator key")
+	}
+
+	valid, err := publicKey.Verify(append([]byte(signaturePrefix), s.Payload...), s.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify manifest signature")
+	}
+
+	if !valid {
+		return nil, errors.New("manifest signature is not valid")
+	}
+
+	return Parse(s.Payload)
+}
+
+// Load reads the signed manifest at path and verifies it against the operators
+func Load(path string, operators []peer.ID) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest")
+	}
+
+	signed := SignedManifest{}
+	err = json.Unmarshal(content, &signed)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode signed manifest")
+	}
+
+	return signed.Verify(operators)
+}
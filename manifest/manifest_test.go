@@ -0,0 +1,122 @@
+package manifest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPiece = "baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"
+
+func newOperator(t *testing.T) (crypto.PrivKey, peer.ID) {
+	t.Helper()
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	return key, id
+}
+
+func TestParse(t *testing.T) {
+	pieceCid := cid.MustParse(testPiece)
+	for name, content := range map[string]string{
+		"csv":  "pieceCid,pieceSize,payloadCid,label\n" + testPiece + ",256\n",
+		"json": `[{"pieceCid": "` + testPiece + `", "pieceSize": 256, "label": "dataset"}]`,
+	} {
+		manifest, err := Parse([]byte(content))
+		if err != nil {
+			t.Fatalf("%s: err is not null: %v", name, err)
+		}
+
+		if manifest.Len() != 1 || len(manifest.Version) != 64 {
+			t.Fatalf("%s: unexpected manifest %v", name, manifest)
+		}
+
+		if manifest.Check(pieceCid, 256) != nil {
+			t.Fatalf("%s: expected listed piece to pass", name)
+		}
+
+		if manifest.Check(pieceCid, 512) == nil {
+			t.Fatalf("%s: expected piece with another size to fail", name)
+		}
+
+		if manifest.Check(cid.MustParse("bafkqaaa"), 256) == nil {
+			t.Fatalf("%s: expected unlisted piece to fail", name)
+		}
+	}
+
+	for _, content := range []string{
+		testPiece + ",255\n",
+		"notacid,256\n",
+		testPiece + "\n",
+		testPiece + ",256\n" + testPiece + ",512\n",
+	} {
+		_, err := Parse([]byte(content))
+		if err == nil {
+			t.Fatalf("expected manifest %q to be invalid", content)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	key, operator := newOperator(t)
+	_, other := newOperator(t)
+	signed, err := Sign(key, []byte(testPiece+",256\n"))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	content, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	err = os.WriteFile(path, content, 0o600)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	manifest, err := Load(path, []peer.ID{operator})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if manifest.Check(cid.MustParse(testPiece), abi.PaddedPieceSize(256)) != nil {
+		t.Fatalf("expected signed piece to be listed")
+	}
+
+	_, err = Load(path, []peer.ID{other})
+	if err == nil {
+		t.Fatalf("expected manifest from another key to be refused")
+	}
+
+	signed.Payload = []byte(testPiece + ",512\n")
+	_, err = signed.Verify([]peer.ID{operator})
+	if err == nil {
+		t.Fatalf("expected tampered manifest to be refused")
+	}
+}
+
+func TestParseErrorLine(t *testing.T) {
+	content := "pieceCid,pieceSize,payloadCid,label\n" +
+		testPiece + ",256,,\"two\nlines\"\n" +
+		"bafkqaaa,abc\n"
+	_, err := Parse([]byte(content))
+	if err == nil || !strings.Contains(err.Error(), "line 4 ") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package message
+
+import (
+	"encoding/hex"
+	"github.com/data-preservation-programs/filsigner-relayed/chainmsg"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/removedatacap"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// rawPrefix is prepended to raw messages as in FRC-0102, so that a signed raw message can never be a valid
+// signature for a chain message, a deal proposal or any other CBOR encoded payload
+const rawPrefix = "\x19Filecoin Signed Message:\n"
+
+// Raw returns the bytes signed for a raw message: the prefix, the decimal length of the message and the message
+func Raw(payload []byte) []byte {
+	length := strconv.Itoa(len(payload))
+	signed := make([]byte, 0, len(rawPrefix)+len(length)+len(payload))
+	signed = append(signed, rawPrefix...)
+	signed = append(signed, length...)
+	return append(signed, payload...)
+}
+
+// ParseUUID decodes a deal UUID in the canonical 8-4-4-4-12 hex form
+func ParseUUID(value string) ([16]byte, error) {
+	var uuid [16]byte
+	if len(value) != 36 || value[8] != '-' || value[13] != '-' || value[18] != '-' || value[23] != '-' {
+		return uuid, errors.Errorf("invalid uuid %s", value)
+	}
+
+	_, err := hex.Decode(uuid[:], []byte(strings.ReplaceAll(value, "-", "")))
+	if err != nil {
+		return uuid, errors.Wrapf(err, "invalid uuid %s", value)
+	}
+
+	return uuid, nil
+}
+
+// FormatUUID encodes a deal UUID in the canonical 8-4-4-4-12 hex form
+func FormatUUID(uuid [16]byte) string {
+	encoded := hex.EncodeToString(uuid[:])
+	return encoded[:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" + encoded[16:20] + "-" + encoded[20:]
+}
+
+// SigningBytes returns the bytes the wallet signs for the payload of the message type
+func SigningBytes(msgType string, payload []byte) ([]byte, error) {
+	switch msgType {
+	case model.MessageTypeDealProposal:
+		return payload, nil
+	case model.MessageTypeDealStatusRequest:
+		// Boost verifies the signature over the binary UUID
+		if len(payload) != 16 {
+			return nil, errors.Errorf("deal status request must be a 16 byte uuid, got %d bytes", len(payload))
+		}
+		return payload, nil
+	case model.MessageTypeChainMessage:
+		msgCid, err := chainmsg.Cid(payload)
+		if err != nil {
+			return nil, err
+		}
+		return msgCid.Bytes(), nil
+	case model.MessageTypeRemoveDataCapProposal:
+		return removedatacap.SigningBytes(payload), nil
+	case model.MessageTypeRaw:
+		return Raw(payload), nil
+	default:
+		return nil, errors.Errorf("unsupported message type %s", msgType)
+	}
+}
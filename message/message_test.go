@@ -0,0 +1,49 @@
+package message
+
+import (
+	"bytes"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"testing"
+)
+
+func TestSigningBytes(t *testing.T) {
+	signed, err := SigningBytes(model.MessageTypeRaw, []byte("hello"))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if !bytes.Equal(signed, []byte("\x19Filecoin Signed Message:\n5hello")) {
+		t.Fatalf("unexpected signing bytes: %q", signed)
+	}
+
+	signed, err = SigningBytes(model.MessageTypeDealProposal, []byte{0x8b})
+	if err != nil || !bytes.Equal(signed, []byte{0x8b}) {
+		t.Fatalf("unexpected signing bytes: %x, %v", signed, err)
+	}
+
+	_, err = SigningBytes("BlockHeader", nil)
+	if err == nil {
+		t.Fatal("expected an error for an unsupported message type")
+	}
+}
+
+func TestUUID(t *testing.T) {
+	uuid, err := ParseUUID("0b2a7d9e-4c1f-4e8a-9f3b-6d5c2a1e0f47")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if uuid[0] != 0x0b || uuid[15] != 0x47 || FormatUUID(uuid) != "0b2a7d9e-4c1f-4e8a-9f3b-6d5c2a1e0f47" {
+		t.Fatalf("unexpected uuid: %x", uuid)
+	}
+
+	for _, value := range []string{"0b2a7d9e4c1f4e8a9f3b6d5c2a1e0f47", "0b2a7d9e-4c1f-4e8a-9f3b-6d5c2a1e0f4z"} {
+		_, err = ParseUUID(value)
+		if err == nil {
+			t.Fatalf("expected an error for %s", value)
+		}
+	}
+
+	_, err = SigningBytes(model.MessageTypeDealStatusRequest, uuid[:15])
+	if err == nil {
+		t.Fatal("expected an error for a short uuid")
+	}
+}
@@ -0,0 +1,16 @@
+package model
+
+//go:generate go run github.com/hannahhoward/cbor-gen-for --map-encoding SignRequest
+
+// SignRequest is a typed request of the sign protocol
+type SignRequest struct {
+	// Type is the message type of the payload, such as DealProposal
+	Type string
+	// Wallet is the address to sign with. Deal proposals are signed for their client if it is empty.
+	Wallet  string
+	Payload []byte
+	// DealUUID is the Boost deal UUID of a deal proposal. It is recorded so that deal status requests can be signed for the deal.
+	DealUUID string
+	// NetworkVersion selects the market actor version deal proposals are decoded with. The signer default is used if it is 0.
+	NetworkVersion uint64
+}
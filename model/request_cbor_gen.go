@@ -0,0 +1,253 @@
+// Code generated by github.com/whyrusleeping/cbor-gen. DO NOT EDIT.
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	cid "github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	xerrors "golang.org/x/xerrors"
+)
+
+var _ = xerrors.Errorf
+var _ = cid.Undef
+var _ = sort.Sort
+
+func (t *SignRequest) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{165}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Type (string) (string)
+	if len("Type") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Type\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Type"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Type")); err != nil {
+		return err
+	}
+
+	if len(t.Type) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Type was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Type))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Type)); err != nil {
+		return err
+	}
+
+	// t.Wallet (string) (string)
+	if len("Wallet") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Wallet\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Wallet"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Wallet")); err != nil {
+		return err
+	}
+
+	if len(t.Wallet) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Wallet was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Wallet))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Wallet)); err != nil {
+		return err
+	}
+
+	// t.Payload ([]uint8) (slice)
+	if len("Payload") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Payload\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Payload"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Payload")); err != nil {
+		return err
+	}
+
+	if len(t.Payload) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Payload was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajByteString, uint64(len(t.Payload))); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(t.Payload[:]); err != nil {
+		return err
+	}
+
+	// t.DealUUID (string) (string)
+	if len("DealUUID") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DealUUID\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("DealUUID"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DealUUID")); err != nil {
+		return err
+	}
+
+	if len(t.DealUUID) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.DealUUID was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.DealUUID))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.DealUUID)); err != nil {
+		return err
+	}
+
+	// t.NetworkVersion (uint64) (uint64)
+	if len("NetworkVersion") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"NetworkVersion\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("NetworkVersion"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("NetworkVersion")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.NetworkVersion)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *SignRequest) UnmarshalCBOR(r io.Reader) error {
+	*t = SignRequest{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("SignRequest: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Type (string) (string)
+		case "Type":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Type = string(sval)
+			}
+			// t.Wallet (string) (string)
+		case "Wallet":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Wallet = string(sval)
+			}
+			// t.Payload ([]uint8) (slice)
+		case "Payload":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Payload: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Payload = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(br, t.Payload[:]); err != nil {
+				return err
+			}
+			// t.DealUUID (string) (string)
+		case "DealUUID":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.DealUUID = string(sval)
+			}
+			// t.NetworkVersion (uint64) (uint64)
+		case "NetworkVersion":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.NetworkVersion = uint64(extra)
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
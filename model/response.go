@@ -13,6 +13,21 @@ const (
 	WalletSignError
 	MarshalSignatureError
 	EncodeResponseError
+	PendingApproval
+	ApprovalRejected
+	TicketNotFound
+	ApprovalQueueError
+	CosignatureRequired
+	MessageTypeNotAllowed
+	UnknownDeal
+	ChainMessageNotAllowed
+	DataCapRemovalNotAllowed
+	EpochOutOfWindow
+	DataCapBudgetExceeded
+	ReplicaLimitExceeded
+	ProviderReplicaLimitExceeded
+	ProviderGroupLimitExceeded
+	PieceNotInManifest
 )
 
 var StatusCodeString = []string{
@@ -26,12 +41,142 @@ var StatusCodeString = []string{
 	"WalletSignError",
 	"MarshalSignatureError",
 	"EncodeResponseError",
+	"PendingApproval",
+	"ApprovalRejected",
+	"TicketNotFound",
+	"ApprovalQueueError",
+	"CosignatureRequired",
+	"MessageTypeNotAllowed",
+	"UnknownDeal",
+	"ChainMessageNotAllowed",
+	"DataCapRemovalNotAllowed",
+	"EpochOutOfWindow",
+	"DataCapBudgetExceeded",
+	"ReplicaLimitExceeded",
+	"ProviderReplicaLimitExceeded",
+	"ProviderGroupLimitExceeded",
+	"PieceNotInManifest",
 }
 
-//go:generate go run github.com/hannahhoward/cbor-gen-for --map-encoding SignerResponse
+//go:generate go run github.com/hannahhoward/cbor-gen-for --map-encoding SignerResponse SignerInfo WalletInfo ProtocolInfo MessageTypeInfo PolicyInfo RuleInfo BudgetInfo PingResponse RemarshalMismatch
 
 type SignerResponse struct {
 	Code      StatusCode
 	Message   string
 	Signature []byte
+	// Ticket identifies a proposal parked for manual approval, which can be polled with the ticket protocol
+	Ticket string
+	// Mismatch describes the first field that does not round-trip, for ProposalRemarshalMismatch responses
+	Mismatch *RemarshalMismatch
+	// Signed is the signed payload for the message types that return one, such as the SignedMessage of a chain message
+	Signed []byte
+	// RemainingDataCap is the least datacap left in the budgets of the client after a verified proposal, if it has any
+	RemainingDataCap *uint64
+}
+
+// RemarshalMismatch locates where the proposal bytes differ from the proposal re-marshalled by the signer
+type RemarshalMismatch struct {
+	// Offset is the position of the first differing byte in the proposal bytes sent by the requester
+	Offset uint64
+	// Field is the path of the field the difference is in, such as Label or ProviderCollateral
+	Field        string
+	Original     []byte
+	Remarshalled []byte
+}
+
+const (
+	MessageTypeDealProposal = "DealProposal"
+	// MessageTypeDealStatusRequest is the 16 byte UUID of a Boost deal, signed by the client to query the deal status
+	MessageTypeDealStatusRequest = "DealStatusRequest"
+	// MessageTypeChainMessage is a chain message, of which the CID is signed as in Lotus
+	MessageTypeChainMessage = "ChainMessage"
+	// MessageTypeRemoveDataCapProposal is a verified registry RemoveDataCapProposal, signed by a verifier with the
+	// domain separation prefix of the verified registry actor
+	MessageTypeRemoveDataCapProposal = "RemoveDataCapProposal"
+	// MessageTypeRaw is arbitrary bytes, which are signed with the FRC-0102 prefix
+	MessageTypeRaw = "Raw"
+)
+
+// MessageTypes are the message types of the sign protocol
+var MessageTypes = []string{MessageTypeDealProposal, MessageTypeDealStatusRequest, MessageTypeChainMessage, MessageTypeRemoveDataCapProposal, MessageTypeRaw}
+
+// SignerInfo is the capability document returned by the info protocol
+type SignerInfo struct {
+	Code         StatusCode
+	Message      string
+	Wallets      []WalletInfo
+	Protocols    []ProtocolInfo
+	MessageTypes []MessageTypeInfo
+	Policy       PolicyInfo
+}
+
+// WalletInfo describes a client address the signer holds the key for
+type WalletInfo struct {
+	Address string
+	// IDAddress is the resolved ID address, if any
+	IDAddress string
+	// Cosign is set if signing for the wallet requires co-signed operator approvals
+	Cosign bool
+	// MessageTypes are the message types the wallet signs
+	MessageTypes []MessageTypeInfo
+}
+
+type ProtocolInfo struct {
+	ID string
+}
+
+type MessageTypeInfo struct {
+	Name string
+}
+
+// PolicyInfo describes the limits the signer enforces before signing
+type PolicyInfo struct {
+	ApprovalPieceSizeAbove uint64
+	ApprovalPriceAbove     string
+	ApprovalNewProviders   bool
+	// ChainMessageRules and RemoveDataCapRules are the typed message rules of each wallet
+	ChainMessageRules  []RuleInfo
+	RemoveDataCapRules []RuleInfo
+	// The epoch window of deal proposals, in epochs. Zero fields are not limited.
+	StartMinLead int64
+	StartMaxLead int64
+	MinDuration  int64
+	MaxDuration  int64
+	EndMaxLead   int64
+	// DataCapBudgets are the datacap budgets of verified proposals
+	DataCapBudgets []BudgetInfo
+	// The replication limits of each piece. Zero fields are not limited.
+	MaxReplicas            uint64
+	MaxReplicasPerProvider uint64
+	MaxReplicasPerGroup    uint64
+	// ManifestVersion is the version of the piece manifest proposals are checked against, if there is one
+	ManifestVersion string
+}
+
+// RuleInfo is a typed message rule of a wallet, in the format of its flag without the wallet address
+type RuleInfo struct {
+	Wallet string
+	Rule   string
+}
+
+// BudgetInfo is a datacap budget of verified proposals
+type BudgetInfo struct {
+	// Wallet is the client wallet of the budget, or empty for every wallet
+	Wallet string
+	// Scope is what the budget is kept for: wallet, requester or provider
+	Scope string
+	// Window is the rolling window of the budget in seconds
+	Window uint64
+	// Limit is the datacap of the window in bytes
+	Limit uint64
+}
+
+// PingResponse is the liveness status returned by the ping protocol
+type PingResponse struct {
+	Code          StatusCode
+	Message       string
+	UptimeSeconds uint64
+	// Reservations is the number of relays the signer currently holds a reservation with
+	Reservations     uint64
+	KeystoreUnlocked bool
 }
@@ -21,7 +21,7 @@ func (t *SignerResponse) MarshalCBOR(w io.Writer) error {
 		_, err := w.Write(cbg.CborNull)
 		return err
 	}
-	if _, err := w.Write([]byte{163}); err != nil {
+	if _, err := w.Write([]byte{167}); err != nil {
 		return err
 	}
 
@@ -89,6 +89,92 @@ func (t *SignerResponse) MarshalCBOR(w io.Writer) error {
 	if _, err := w.Write(t.Signature[:]); err != nil {
 		return err
 	}
+
+	// t.Ticket (string) (string)
+	if len("Ticket") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Ticket\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Ticket"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Ticket")); err != nil {
+		return err
+	}
+
+	if len(t.Ticket) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Ticket was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Ticket))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Ticket)); err != nil {
+		return err
+	}
+
+	// t.Mismatch (model.RemarshalMismatch) (struct)
+	if len("Mismatch") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Mismatch\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Mismatch"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Mismatch")); err != nil {
+		return err
+	}
+
+	if err := t.Mismatch.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Signed ([]uint8) (slice)
+	if len("Signed") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Signed\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Signed"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Signed")); err != nil {
+		return err
+	}
+
+	if len(t.Signed) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Signed was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajByteString, uint64(len(t.Signed))); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(t.Signed[:]); err != nil {
+		return err
+	}
+
+	// t.RemainingDataCap (uint64) (uint64)
+	if len("RemainingDataCap") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RemainingDataCap\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("RemainingDataCap"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RemainingDataCap")); err != nil {
+		return err
+	}
+
+	if t.RemainingDataCap == nil {
+		if _, err := w.Write(cbg.CborNull); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(*t.RemainingDataCap)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -173,6 +259,2207 @@ func (t *SignerResponse) UnmarshalCBOR(r io.Reader) error {
 			if _, err := io.ReadFull(br, t.Signature[:]); err != nil {
 				return err
 			}
+			// t.Ticket (string) (string)
+		case "Ticket":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Ticket = string(sval)
+			}
+			// t.Mismatch (model.RemarshalMismatch) (struct)
+		case "Mismatch":
+
+			{
+
+				b, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				if b != cbg.CborNull[0] {
+					if err := br.UnreadByte(); err != nil {
+						return err
+					}
+					t.Mismatch = new(RemarshalMismatch)
+					if err := t.Mismatch.UnmarshalCBOR(br); err != nil {
+						return xerrors.Errorf("unmarshaling t.Mismatch pointer: %w", err)
+					}
+				}
+
+			}
+			// t.Signed ([]uint8) (slice)
+		case "Signed":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Signed: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Signed = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(br, t.Signed[:]); err != nil {
+				return err
+			}
+			// t.RemainingDataCap (uint64) (uint64)
+		case "RemainingDataCap":
+
+			{
+
+				b, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				if b != cbg.CborNull[0] {
+					if err := br.UnreadByte(); err != nil {
+						return err
+					}
+					maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+					if err != nil {
+						return err
+					}
+					if maj != cbg.MajUnsignedInt {
+						return fmt.Errorf("wrong type for uint64 field")
+					}
+					typed := uint64(extra)
+					t.RemainingDataCap = &typed
+				}
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *SignerInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{166}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Code (model.StatusCode) (uint64)
+	if len("Code") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Code\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Code"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Code")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Code)); err != nil {
+		return err
+	}
+
+	// t.Message (string) (string)
+	if len("Message") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Message\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Message"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Message")); err != nil {
+		return err
+	}
+
+	if len(t.Message) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Message was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Message))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Message)); err != nil {
+		return err
+	}
+
+	// t.Wallets ([]model.WalletInfo) (slice)
+	if len("Wallets") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Wallets\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Wallets"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Wallets")); err != nil {
+		return err
+	}
+
+	if len(t.Wallets) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Wallets was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.Wallets))); err != nil {
+		return err
+	}
+	for _, v := range t.Wallets {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.Protocols ([]model.ProtocolInfo) (slice)
+	if len("Protocols") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Protocols\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Protocols"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Protocols")); err != nil {
+		return err
+	}
+
+	if len(t.Protocols) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.Protocols was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.Protocols))); err != nil {
+		return err
+	}
+	for _, v := range t.Protocols {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.MessageTypes ([]model.MessageTypeInfo) (slice)
+	if len("MessageTypes") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MessageTypes\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("MessageTypes"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MessageTypes")); err != nil {
+		return err
+	}
+
+	if len(t.MessageTypes) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.MessageTypes was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.MessageTypes))); err != nil {
+		return err
+	}
+	for _, v := range t.MessageTypes {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.Policy (model.PolicyInfo) (struct)
+	if len("Policy") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Policy\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Policy"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Policy")); err != nil {
+		return err
+	}
+
+	if err := t.Policy.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *SignerInfo) UnmarshalCBOR(r io.Reader) error {
+	*t = SignerInfo{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("SignerInfo: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Code (model.StatusCode) (uint64)
+		case "Code":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Code = StatusCode(extra)
+
+			}
+			// t.Message (string) (string)
+		case "Message":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Message = string(sval)
+			}
+			// t.Wallets ([]model.WalletInfo) (slice)
+		case "Wallets":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Wallets: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.Wallets = make([]WalletInfo, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v WalletInfo
+				if err := v.UnmarshalCBOR(br); err != nil {
+					return err
+				}
+
+				t.Wallets[i] = v
+			}
+
+			// t.Protocols ([]model.ProtocolInfo) (slice)
+		case "Protocols":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.Protocols: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.Protocols = make([]ProtocolInfo, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v ProtocolInfo
+				if err := v.UnmarshalCBOR(br); err != nil {
+					return err
+				}
+
+				t.Protocols[i] = v
+			}
+
+			// t.MessageTypes ([]model.MessageTypeInfo) (slice)
+		case "MessageTypes":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.MessageTypes: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.MessageTypes = make([]MessageTypeInfo, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v MessageTypeInfo
+				if err := v.UnmarshalCBOR(br); err != nil {
+					return err
+				}
+
+				t.MessageTypes[i] = v
+			}
+
+			// t.Policy (model.PolicyInfo) (struct)
+		case "Policy":
+
+			{
+
+				if err := t.Policy.UnmarshalCBOR(br); err != nil {
+					return xerrors.Errorf("unmarshaling t.Policy: %w", err)
+				}
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *WalletInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{164}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Address (string) (string)
+	if len("Address") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Address\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Address"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Address")); err != nil {
+		return err
+	}
+
+	if len(t.Address) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Address was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Address))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Address)); err != nil {
+		return err
+	}
+
+	// t.IDAddress (string) (string)
+	if len("IDAddress") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"IDAddress\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("IDAddress"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("IDAddress")); err != nil {
+		return err
+	}
+
+	if len(t.IDAddress) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.IDAddress was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.IDAddress))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.IDAddress)); err != nil {
+		return err
+	}
+
+	// t.Cosign (bool) (bool)
+	if len("Cosign") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Cosign\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Cosign"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Cosign")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.Cosign); err != nil {
+		return err
+	}
+
+	// t.MessageTypes ([]model.MessageTypeInfo) (slice)
+	if len("MessageTypes") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MessageTypes\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("MessageTypes"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MessageTypes")); err != nil {
+		return err
+	}
+
+	if len(t.MessageTypes) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.MessageTypes was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.MessageTypes))); err != nil {
+		return err
+	}
+	for _, v := range t.MessageTypes {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *WalletInfo) UnmarshalCBOR(r io.Reader) error {
+	*t = WalletInfo{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("WalletInfo: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Address (string) (string)
+		case "Address":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Address = string(sval)
+			}
+			// t.IDAddress (string) (string)
+		case "IDAddress":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.IDAddress = string(sval)
+			}
+			// t.Cosign (bool) (bool)
+		case "Cosign":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+			if maj != cbg.MajOther {
+				return fmt.Errorf("booleans must be major type 7")
+			}
+			switch extra {
+			case 20:
+				t.Cosign = false
+			case 21:
+				t.Cosign = true
+			default:
+				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+			}
+			// t.MessageTypes ([]model.MessageTypeInfo) (slice)
+		case "MessageTypes":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.MessageTypes: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.MessageTypes = make([]MessageTypeInfo, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v MessageTypeInfo
+				if err := v.UnmarshalCBOR(br); err != nil {
+					return err
+				}
+
+				t.MessageTypes[i] = v
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *ProtocolInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{161}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.ID (string) (string)
+	if len("ID") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ID\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("ID"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ID")); err != nil {
+		return err
+	}
+
+	if len(t.ID) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.ID was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.ID))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.ID)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *ProtocolInfo) UnmarshalCBOR(r io.Reader) error {
+	*t = ProtocolInfo{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("ProtocolInfo: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.ID (string) (string)
+		case "ID":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.ID = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *MessageTypeInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{161}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Name (string) (string)
+	if len("Name") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Name\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Name"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Name")); err != nil {
+		return err
+	}
+
+	if len(t.Name) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Name was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Name))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Name)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *MessageTypeInfo) UnmarshalCBOR(r io.Reader) error {
+	*t = MessageTypeInfo{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("MessageTypeInfo: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Name (string) (string)
+		case "Name":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Name = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *PolicyInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{175}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.ApprovalPieceSizeAbove (uint64) (uint64)
+	if len("ApprovalPieceSizeAbove") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ApprovalPieceSizeAbove\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("ApprovalPieceSizeAbove"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ApprovalPieceSizeAbove")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.ApprovalPieceSizeAbove)); err != nil {
+		return err
+	}
+
+	// t.ApprovalPriceAbove (string) (string)
+	if len("ApprovalPriceAbove") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ApprovalPriceAbove\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("ApprovalPriceAbove"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ApprovalPriceAbove")); err != nil {
+		return err
+	}
+
+	if len(t.ApprovalPriceAbove) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.ApprovalPriceAbove was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.ApprovalPriceAbove))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.ApprovalPriceAbove)); err != nil {
+		return err
+	}
+
+	// t.ApprovalNewProviders (bool) (bool)
+	if len("ApprovalNewProviders") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ApprovalNewProviders\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("ApprovalNewProviders"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ApprovalNewProviders")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.ApprovalNewProviders); err != nil {
+		return err
+	}
+
+	// t.ChainMessageRules ([]model.RuleInfo) (slice)
+	if len("ChainMessageRules") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ChainMessageRules\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("ChainMessageRules"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ChainMessageRules")); err != nil {
+		return err
+	}
+
+	if len(t.ChainMessageRules) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.ChainMessageRules was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.ChainMessageRules))); err != nil {
+		return err
+	}
+	for _, v := range t.ChainMessageRules {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.RemoveDataCapRules ([]model.RuleInfo) (slice)
+	if len("RemoveDataCapRules") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RemoveDataCapRules\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("RemoveDataCapRules"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RemoveDataCapRules")); err != nil {
+		return err
+	}
+
+	if len(t.RemoveDataCapRules) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.RemoveDataCapRules was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.RemoveDataCapRules))); err != nil {
+		return err
+	}
+	for _, v := range t.RemoveDataCapRules {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.StartMinLead (int64) (int64)
+	if len("StartMinLead") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"StartMinLead\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("StartMinLead"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("StartMinLead")); err != nil {
+		return err
+	}
+
+	if t.StartMinLead >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.StartMinLead)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.StartMinLead-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.StartMaxLead (int64) (int64)
+	if len("StartMaxLead") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"StartMaxLead\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("StartMaxLead"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("StartMaxLead")); err != nil {
+		return err
+	}
+
+	if t.StartMaxLead >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.StartMaxLead)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.StartMaxLead-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.MinDuration (int64) (int64)
+	if len("MinDuration") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MinDuration\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("MinDuration"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MinDuration")); err != nil {
+		return err
+	}
+
+	if t.MinDuration >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.MinDuration)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.MinDuration-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.MaxDuration (int64) (int64)
+	if len("MaxDuration") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MaxDuration\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("MaxDuration"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MaxDuration")); err != nil {
+		return err
+	}
+
+	if t.MaxDuration >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.MaxDuration)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.MaxDuration-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.EndMaxLead (int64) (int64)
+	if len("EndMaxLead") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"EndMaxLead\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("EndMaxLead"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("EndMaxLead")); err != nil {
+		return err
+	}
+
+	if t.EndMaxLead >= 0 {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.EndMaxLead)); err != nil {
+			return err
+		}
+	} else {
+		if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajNegativeInt, uint64(-t.EndMaxLead-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.DataCapBudgets ([]model.BudgetInfo) (slice)
+	if len("DataCapBudgets") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DataCapBudgets\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("DataCapBudgets"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DataCapBudgets")); err != nil {
+		return err
+	}
+
+	if len(t.DataCapBudgets) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.DataCapBudgets was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajArray, uint64(len(t.DataCapBudgets))); err != nil {
+		return err
+	}
+	for _, v := range t.DataCapBudgets {
+		if err := v.MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+
+	// t.MaxReplicas (uint64) (uint64)
+	if len("MaxReplicas") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MaxReplicas\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("MaxReplicas"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MaxReplicas")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.MaxReplicas)); err != nil {
+		return err
+	}
+
+	// t.MaxReplicasPerProvider (uint64) (uint64)
+	if len("MaxReplicasPerProvider") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MaxReplicasPerProvider\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("MaxReplicasPerProvider"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MaxReplicasPerProvider")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.MaxReplicasPerProvider)); err != nil {
+		return err
+	}
+
+	// t.MaxReplicasPerGroup (uint64) (uint64)
+	if len("MaxReplicasPerGroup") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"MaxReplicasPerGroup\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("MaxReplicasPerGroup"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("MaxReplicasPerGroup")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.MaxReplicasPerGroup)); err != nil {
+		return err
+	}
+
+	// t.ManifestVersion (string) (string)
+	if len("ManifestVersion") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"ManifestVersion\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("ManifestVersion"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("ManifestVersion")); err != nil {
+		return err
+	}
+
+	if len(t.ManifestVersion) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.ManifestVersion was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.ManifestVersion))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.ManifestVersion)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *PolicyInfo) UnmarshalCBOR(r io.Reader) error {
+	*t = PolicyInfo{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("PolicyInfo: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.ApprovalPieceSizeAbove (uint64) (uint64)
+		case "ApprovalPieceSizeAbove":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.ApprovalPieceSizeAbove = uint64(extra)
+
+			}
+			// t.ApprovalPriceAbove (string) (string)
+		case "ApprovalPriceAbove":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.ApprovalPriceAbove = string(sval)
+			}
+			// t.ApprovalNewProviders (bool) (bool)
+		case "ApprovalNewProviders":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+			if maj != cbg.MajOther {
+				return fmt.Errorf("booleans must be major type 7")
+			}
+			switch extra {
+			case 20:
+				t.ApprovalNewProviders = false
+			case 21:
+				t.ApprovalNewProviders = true
+			default:
+				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+			}
+			// t.ChainMessageRules ([]model.RuleInfo) (slice)
+		case "ChainMessageRules":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.ChainMessageRules: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.ChainMessageRules = make([]RuleInfo, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v RuleInfo
+				if err := v.UnmarshalCBOR(br); err != nil {
+					return err
+				}
+
+				t.ChainMessageRules[i] = v
+			}
+
+			// t.RemoveDataCapRules ([]model.RuleInfo) (slice)
+		case "RemoveDataCapRules":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.RemoveDataCapRules: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.RemoveDataCapRules = make([]RuleInfo, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v RuleInfo
+				if err := v.UnmarshalCBOR(br); err != nil {
+					return err
+				}
+
+				t.RemoveDataCapRules[i] = v
+			}
+
+			// t.StartMinLead (int64) (int64)
+		case "StartMinLead":
+			{
+				maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.StartMinLead = int64(extraI)
+			}
+			// t.StartMaxLead (int64) (int64)
+		case "StartMaxLead":
+			{
+				maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.StartMaxLead = int64(extraI)
+			}
+			// t.MinDuration (int64) (int64)
+		case "MinDuration":
+			{
+				maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.MinDuration = int64(extraI)
+			}
+			// t.MaxDuration (int64) (int64)
+		case "MaxDuration":
+			{
+				maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.MaxDuration = int64(extraI)
+			}
+			// t.EndMaxLead (int64) (int64)
+		case "EndMaxLead":
+			{
+				maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative oveflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.EndMaxLead = int64(extraI)
+			}
+			// t.DataCapBudgets ([]model.BudgetInfo) (slice)
+		case "DataCapBudgets":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.DataCapBudgets: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.DataCapBudgets = make([]BudgetInfo, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				var v BudgetInfo
+				if err := v.UnmarshalCBOR(br); err != nil {
+					return err
+				}
+
+				t.DataCapBudgets[i] = v
+			}
+
+			// t.MaxReplicas (uint64) (uint64)
+		case "MaxReplicas":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.MaxReplicas = uint64(extra)
+
+			}
+			// t.MaxReplicasPerProvider (uint64) (uint64)
+		case "MaxReplicasPerProvider":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.MaxReplicasPerProvider = uint64(extra)
+
+			}
+			// t.MaxReplicasPerGroup (uint64) (uint64)
+		case "MaxReplicasPerGroup":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.MaxReplicasPerGroup = uint64(extra)
+
+			}
+			// t.ManifestVersion (string) (string)
+		case "ManifestVersion":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.ManifestVersion = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *RuleInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{162}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Wallet (string) (string)
+	if len("Wallet") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Wallet\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Wallet"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Wallet")); err != nil {
+		return err
+	}
+
+	if len(t.Wallet) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Wallet was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Wallet))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Wallet)); err != nil {
+		return err
+	}
+
+	// t.Rule (string) (string)
+	if len("Rule") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Rule\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Rule"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Rule")); err != nil {
+		return err
+	}
+
+	if len(t.Rule) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Rule was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Rule))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Rule)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *RuleInfo) UnmarshalCBOR(r io.Reader) error {
+	*t = RuleInfo{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("RuleInfo: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Wallet (string) (string)
+		case "Wallet":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Wallet = string(sval)
+			}
+			// t.Rule (string) (string)
+		case "Rule":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Rule = string(sval)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *BudgetInfo) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{164}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Wallet (string) (string)
+	if len("Wallet") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Wallet\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Wallet"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Wallet")); err != nil {
+		return err
+	}
+
+	if len(t.Wallet) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Wallet was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Wallet))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Wallet)); err != nil {
+		return err
+	}
+
+	// t.Scope (string) (string)
+	if len("Scope") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Scope\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Scope"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Scope")); err != nil {
+		return err
+	}
+
+	if len(t.Scope) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Scope was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Scope))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Scope)); err != nil {
+		return err
+	}
+
+	// t.Window (uint64) (uint64)
+	if len("Window") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Window\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Window"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Window")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Window)); err != nil {
+		return err
+	}
+
+	// t.Limit (uint64) (uint64)
+	if len("Limit") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Limit\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Limit"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Limit")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Limit)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *BudgetInfo) UnmarshalCBOR(r io.Reader) error {
+	*t = BudgetInfo{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("BudgetInfo: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Wallet (string) (string)
+		case "Wallet":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Wallet = string(sval)
+			}
+			// t.Scope (string) (string)
+		case "Scope":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Scope = string(sval)
+			}
+			// t.Window (uint64) (uint64)
+		case "Window":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Window = uint64(extra)
+
+			}
+			// t.Limit (uint64) (uint64)
+		case "Limit":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Limit = uint64(extra)
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *PingResponse) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{165}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Code (model.StatusCode) (uint64)
+	if len("Code") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Code\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Code"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Code")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Code)); err != nil {
+		return err
+	}
+
+	// t.Message (string) (string)
+	if len("Message") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Message\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Message"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Message")); err != nil {
+		return err
+	}
+
+	if len(t.Message) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Message was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Message))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Message)); err != nil {
+		return err
+	}
+
+	// t.UptimeSeconds (uint64) (uint64)
+	if len("UptimeSeconds") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"UptimeSeconds\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("UptimeSeconds"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("UptimeSeconds")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.UptimeSeconds)); err != nil {
+		return err
+	}
+
+	// t.Reservations (uint64) (uint64)
+	if len("Reservations") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Reservations\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Reservations"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Reservations")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Reservations)); err != nil {
+		return err
+	}
+
+	// t.KeystoreUnlocked (bool) (bool)
+	if len("KeystoreUnlocked") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"KeystoreUnlocked\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("KeystoreUnlocked"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("KeystoreUnlocked")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.KeystoreUnlocked); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *PingResponse) UnmarshalCBOR(r io.Reader) error {
+	*t = PingResponse{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("PingResponse: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Code (model.StatusCode) (uint64)
+		case "Code":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Code = StatusCode(extra)
+
+			}
+			// t.Message (string) (string)
+		case "Message":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Message = string(sval)
+			}
+			// t.UptimeSeconds (uint64) (uint64)
+		case "UptimeSeconds":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.UptimeSeconds = uint64(extra)
+
+			}
+			// t.Reservations (uint64) (uint64)
+		case "Reservations":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Reservations = uint64(extra)
+
+			}
+			// t.KeystoreUnlocked (bool) (bool)
+		case "KeystoreUnlocked":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+			if maj != cbg.MajOther {
+				return fmt.Errorf("booleans must be major type 7")
+			}
+			switch extra {
+			case 20:
+				t.KeystoreUnlocked = false
+			case 21:
+				t.KeystoreUnlocked = true
+			default:
+				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
+func (t *RemarshalMismatch) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write([]byte{164}); err != nil {
+		return err
+	}
+
+	scratch := make([]byte, 9)
+
+	// t.Offset (uint64) (uint64)
+	if len("Offset") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Offset\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Offset"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Offset")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajUnsignedInt, uint64(t.Offset)); err != nil {
+		return err
+	}
+
+	// t.Field (string) (string)
+	if len("Field") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Field\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Field"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Field")); err != nil {
+		return err
+	}
+
+	if len(t.Field) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Field was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len(t.Field))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Field)); err != nil {
+		return err
+	}
+
+	// t.Original ([]uint8) (slice)
+	if len("Original") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Original\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Original"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Original")); err != nil {
+		return err
+	}
+
+	if len(t.Original) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Original was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajByteString, uint64(len(t.Original))); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(t.Original[:]); err != nil {
+		return err
+	}
+
+	// t.Remarshalled ([]uint8) (slice)
+	if len("Remarshalled") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Remarshalled\" was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajTextString, uint64(len("Remarshalled"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Remarshalled")); err != nil {
+		return err
+	}
+
+	if len(t.Remarshalled) > cbg.ByteArrayMaxLen {
+		return xerrors.Errorf("Byte array in field t.Remarshalled was too long")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajByteString, uint64(len(t.Remarshalled))); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(t.Remarshalled[:]); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *RemarshalMismatch) UnmarshalCBOR(r io.Reader) error {
+	*t = RemarshalMismatch{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("RemarshalMismatch: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadStringBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Offset (uint64) (uint64)
+		case "Offset":
+
+			{
+
+				maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Offset = uint64(extra)
+
+			}
+			// t.Field (string) (string)
+		case "Field":
+
+			{
+				sval, err := cbg.ReadStringBuf(br, scratch)
+				if err != nil {
+					return err
+				}
+
+				t.Field = string(sval)
+			}
+			// t.Original ([]uint8) (slice)
+		case "Original":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Original: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Original = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(br, t.Original[:]); err != nil {
+				return err
+			}
+			// t.Remarshalled ([]uint8) (slice)
+		case "Remarshalled":
+
+			maj, extra, err = cbg.CborReadHeaderBuf(br, scratch)
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.ByteArrayMaxLen {
+				return fmt.Errorf("t.Remarshalled: byte array too large (%d)", extra)
+			}
+			if maj != cbg.MajByteString {
+				return fmt.Errorf("expected byte array")
+			}
+
+			if extra > 0 {
+				t.Remarshalled = make([]uint8, extra)
+			}
+
+			if _, err := io.ReadFull(br, t.Remarshalled[:]); err != nil {
+				return err
+			}
 
 		default:
 			// Field doesn't exist on this type, so ignore it
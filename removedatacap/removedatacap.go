@@ -0,0 +1,101 @@
+package removedatacap
+
+import (
+	"bytes"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin/v9/verifreg"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// Rule allows a verifier wallet to sign the removal of datacap from a verified client, up to MaxAmount per proposal
+type Rule struct {
+	// Client is the ID address of the verified client
+	Client    address.Address
+	MaxAmount verifreg.DataCap
+}
+
+// String returns the rule in the format ParseRule decodes
+func (r Rule) String() string {
+	if r.MaxAmount.Nil() {
+		return r.Client.String()
+	}
+
+	return r.Client.String() + ":" + r.MaxAmount.String()
+}
+
+// ParseRule decodes a rule given as <client ID address>[:<max amount in bytes>]. The amount is not capped if it is not set.
+func ParseRule(value string) (Rule, error) {
+	client, amount, capped := strings.Cut(value, ":")
+	clientAddr, err := address.NewFromString(client)
+	if err != nil {
+		return Rule{}, errors.Wrapf(err, "failed to decode verified client %s", client)
+	}
+
+	if clientAddr.Protocol() != address.ID {
+		return Rule{}, errors.Errorf("verified client %s must be an ID address", client)
+	}
+
+	rule := Rule{Client: clientAddr}
+	if capped {
+		rule.MaxAmount, err = big.FromString(amount)
+		if err != nil {
+			return Rule{}, errors.Wrapf(err, "failed to decode max amount %s", amount)
+		}
+	}
+
+	return rule, nil
+}
+
+// Decode decodes a RemoveDataCapProposal, which must be encoded canonically as it is signed as it is
+func Decode(data []byte) (*verifreg.RemoveDataCapProposal, error) {
+	proposal := new(verifreg.RemoveDataCapProposal)
+	err := proposal.UnmarshalCBOR(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode remove datacap proposal")
+	}
+
+	buf := new(bytes.Buffer)
+	err = proposal.MarshalCBOR(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode remove datacap proposal")
+	}
+
+	if !bytes.Equal(buf.Bytes(), data) {
+		return nil, errors.New("remove datacap proposal remarshalled does not match the original proposal bytes")
+	}
+
+	return proposal, nil
+}
+
+// SigningBytes returns the bytes the verifier signs for the encoded proposal: the proposal with the
+// domain separation prefix the verified registry actor checks the signature with
+func SigningBytes(payload []byte) []byte {
+	return append([]byte(verifreg.SignatureDomainSeparation_RemoveDataCap), payload...)
+}
+
+// Check returns an error unless one of the rules allows the proposal
+func Check(rules []Rule, proposal *verifreg.RemoveDataCapProposal) error {
+	if proposal.VerifiedClient.Protocol() != address.ID {
+		return errors.Errorf("verified client %s must be an ID address", proposal.VerifiedClient)
+	}
+
+	if proposal.DataCapAmount.Int == nil || proposal.DataCapAmount.Sign() <= 0 {
+		return errors.New("datacap amount to remove must be positive")
+	}
+
+	for _, rule := range rules {
+		if rule.Client != proposal.VerifiedClient {
+			continue
+		}
+
+		if rule.MaxAmount.Int != nil && proposal.DataCapAmount.GreaterThan(rule.MaxAmount) {
+			return errors.Errorf("datacap amount %s exceeds the cap of %s for %s", proposal.DataCapAmount, rule.MaxAmount, proposal.VerifiedClient)
+		}
+
+		return nil
+	}
+
+	return errors.Errorf("removing datacap from %s is not allowed", proposal.VerifiedClient)
+}
@@ -0,0 +1,81 @@
+package removedatacap
+
+import (
+	"bytes"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin/v9/verifreg"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	client, err := address.NewIDAddress(1234)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	rule, err := ParseRule("f01234:1024")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	proposal := &verifreg.RemoveDataCapProposal{VerifiedClient: client, DataCapAmount: big.NewInt(1024), RemovalProposalID: verifreg.RmDcProposalID{ProposalID: 1}}
+	err = Check([]Rule{rule}, proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	proposal.DataCapAmount = big.NewInt(1025)
+	if Check([]Rule{rule}, proposal) == nil {
+		t.Fatal("expected an error for an amount above the cap")
+	}
+
+	uncapped, err := ParseRule("f01234")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	err = Check([]Rule{uncapped}, proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	proposal.DataCapAmount = big.Zero()
+	if Check([]Rule{uncapped}, proposal) == nil {
+		t.Fatal("expected an error for a zero amount")
+	}
+
+	proposal.DataCapAmount = big.NewInt(1)
+	proposal.VerifiedClient = address.TestAddress
+	if Check([]Rule{uncapped}, proposal) == nil {
+		t.Fatal("expected an error for a client that is not allowed")
+	}
+
+	for _, value := range []string{"f01234:x", address.TestAddress.String()} {
+		_, err = ParseRule(value)
+		if err == nil {
+			t.Fatalf("expected an error for %s", value)
+		}
+	}
+}
+
+func TestSigningBytes(t *testing.T) {
+	client, err := address.NewIDAddress(1234)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	err = (&verifreg.RemoveDataCapProposal{VerifiedClient: client, DataCapAmount: big.NewInt(1024)}).MarshalCBOR(buf)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	proposal, err := Decode(buf.Bytes())
+	if err != nil || proposal.VerifiedClient != client {
+		t.Fatalf("unexpected proposal: %v, %v", proposal, err)
+	}
+
+	if !bytes.Equal(SigningBytes(buf.Bytes()), append([]byte("fil_removedatacap:"), buf.Bytes()...)) {
+		t.Fatal("unexpected signing bytes")
+	}
+}
@@ -0,0 +1,131 @@
+// Package replication limits how many deals of a piece are signed, in total, per provider and per provider group
+package replication
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/pkg/errors"
+	"strings"
+	"sync"
+)
+
+// Violation is the limit a proposal would exceed
+type Violation int
+
+const (
+	None Violation = iota
+	// TooManyReplicas is a piece that already has the maximum number of deals
+	TooManyReplicas
+	// TooManyForProvider is a piece that already has the maximum number of deals with the provider
+	TooManyForProvider
+	// TooManyForGroup is a piece that already has the maximum number of deals with the group of the provider
+	TooManyForGroup
+)
+
+// Limits bound the deals signed for each piece. Zero fields disable their limit.
+type Limits struct {
+	MaxReplicas    int
+	MaxPerProvider int
+	MaxPerGroup    int
+	// Groups maps providers to their organization. Providers that are not listed are a group of their own.
+	Groups map[address.Address]string
+}
+
+func (l Limits) Enabled() bool {
+	return l.MaxReplicas > 0 || l.MaxPerProvider > 0 || l.MaxPerGroup > 0
+}
+
+func (l Limits) group(provider address.Address) string {
+	group, ok := l.Groups[provider]
+	if !ok {
+		return provider.String()
+	}
+
+	return group
+}
+
+// Check returns the limit a new deal of the piece with the provider would exceed, given the providers of its signed deals
+func (l Limits) Check(providers []address.Address, provider address.Address) (Violation, error) {
+	if l.MaxReplicas > 0 && len(providers) >= l.MaxReplicas {
+		return TooManyReplicas, errors.Errorf("piece already has %d of at most %d replicas", len(providers), l.MaxReplicas)
+	}
+
+	sameProvider := 0
+	sameGroup := 0
+	group := l.group(provider)
+	for _, p := range providers {
+		if p == provider {
+			sameProvider++
+		}
+		if l.group(p) == group {
+			sameGroup++
+		}
+	}
+
+	if l.MaxPerProvider > 0 && sameProvider >= l.MaxPerProvider {
+		return TooManyForProvider, errors.Errorf("piece already has %d of at most %d deals with provider %s", sameProvider, l.MaxPerProvider, provider)
+	}
+
+	if l.MaxPerGroup > 0 && sameGroup >= l.MaxPerGroup {
+		return TooManyForGroup, errors.Errorf("piece already has %d of at most %d deals with provider group %s", sameGroup, l.MaxPerGroup, group)
+	}
+
+	return None, nil
+}
+
+// ParseGroup decodes a provider group given as <provider address>:<group>
+func ParseGroup(value string) (address.Address, string, error) {
+	provider, group, ok := strings.Cut(value, ":")
+	if !ok || group == "" {
+		return address.Undef, "", errors.Errorf("provider group %s must be given as <provider address>:<group>", value)
+	}
+
+	providerAddr, err := address.NewFromString(provider)
+	if err != nil {
+		return address.Undef, "", errors.Wrapf(err, "failed to decode provider %s", provider)
+	}
+
+	return providerAddr, group, nil
+}
+
+// Tracker keeps the providers of the signed deals of each piece
+type Tracker struct {
+	mu     sync.Mutex
+	pieces map[string][]address.Address
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{pieces: make(map[string][]address.Address)}
+}
+
+// Add adds a signed deal of the piece with the provider
+func (t *Tracker) Add(piece string, provider address.Address) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pieces[piece] = append(t.pieces[piece], provider)
+}
+
+// Remove removes a deal added for a proposal that ended up not signed
+func (t *Tracker) Remove(piece string, provider address.Address) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	providers := t.pieces[piece]
+	for i, p := range providers {
+		if p == provider {
+			t.pieces[piece] = append(providers[:i:i], providers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Reserve adds the deal of the piece with the provider if it is within the limits
+func (t *Tracker) Reserve(limits Limits, piece string, provider address.Address) (Violation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	violation, err := limits.Check(t.pieces[piece], provider)
+	if err != nil {
+		return violation, err
+	}
+
+	t.pieces[piece] = append(t.pieces[piece], provider)
+	return None, nil
+}
@@ -0,0 +1,69 @@
+package replication
+
+import (
+	"github.com/filecoin-project/go-address"
+	"testing"
+)
+
+func TestReserve(t *testing.T) {
+	providers := make([]address.Address, 4)
+	for i := range providers {
+		var err error
+		providers[i], err = address.NewIDAddress(uint64(1000 + i))
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+	}
+
+	limits := Limits{
+		MaxReplicas:    4,
+		MaxPerProvider: 1,
+		MaxPerGroup:    2,
+		Groups:         map[address.Address]string{providers[0]: "org", providers[1]: "org", providers[2]: "org"},
+	}
+	tracker := NewTracker()
+	for _, step := range []struct {
+		provider  address.Address
+		violation Violation
+	}{
+		{provider: providers[0], violation: None},
+		{provider: providers[0], violation: TooManyForProvider},
+		{provider: providers[1], violation: None},
+		{provider: providers[2], violation: TooManyForGroup},
+		{provider: providers[3], violation: None},
+	} {
+		violation, err := tracker.Reserve(limits, "piece", step.provider)
+		if violation != step.violation || (err == nil) != (step.violation == None) {
+			t.Fatalf("unexpected violation %d for %s: %v", violation, step.provider, err)
+		}
+	}
+
+	tracker.Remove("piece", providers[1])
+	violation, err := tracker.Reserve(limits, "piece", providers[2])
+	if violation != None {
+		t.Fatalf("unexpected violation %d: %v", violation, err)
+	}
+
+	tracker.Add("piece", providers[3])
+	violation, _ = tracker.Reserve(Limits{MaxReplicas: 4}, "piece", providers[1])
+	if violation != TooManyReplicas {
+		t.Fatalf("unexpected violation %d", violation)
+	}
+}
+
+func TestParseGroup(t *testing.T) {
+	expected, err := address.NewIDAddress(1000)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	provider, group, err := ParseGroup("f01000:org")
+	if err != nil || provider != expected || group != "org" {
+		t.Fatalf("unexpected group %s %s: %v", provider, group, err)
+	}
+
+	_, _, err = ParseGroup("f01000")
+	if err == nil {
+		t.Fatal("expected an error without a group")
+	}
+}
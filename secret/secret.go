@@ -0,0 +1,93 @@
+package secret
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadFile reads a file holding secrets, refusing files that other users can read.
+// Symlinks are followed, as Kubernetes secret volumes link each key to a timestamped directory.
+func ReadFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat secret file")
+	}
+
+	if info.Mode().Perm()&0o004 != 0 {
+		return nil, errors.Errorf("secret file %s is world-readable (mode %s), remove the read permission for others", path, info.Mode().Perm())
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read secret file")
+	}
+
+	return content, nil
+}
+
+// ReadString reads a single secret value from the file, without surrounding whitespace
+func ReadString(path string) (string, error) {
+	content, err := ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bytes.TrimSpace(content)), nil
+}
+
+// ReadList reads secret values separated by newlines or commas from the file, like a comma separated
+// environment variable with one or more values per line
+func ReadList(path string) ([]string, error) {
+	content, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for _, line := range strings.Split(string(content), "\n") {
+		for _, value := range strings.Split(line, ",") {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// ReadDir reads the list of secret values from every file in the directory.
+// Hidden entries are skipped, which includes the bookkeeping entries of Kubernetes secret volumes.
+func ReadDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read secret directory")
+	}
+
+	var values []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to stat secret file")
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		fileValues, err := ReadList(path)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, fileValues...)
+	}
+
+	return values, nil
+}
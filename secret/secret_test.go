@@ -0,0 +1,55 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	err := os.WriteFile(path, []byte(" value\n"), 0o644)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	_, err = ReadString(path)
+	if err == nil {
+		t.Fatalf("expected world-readable secret file to be refused")
+	}
+
+	err = os.Chmod(path, 0o640)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	value, err := ReadString(path)
+	if err != nil || value != "value" {
+		t.Fatalf("unexpected value: %q %v", value, err)
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a":      "key1,key2\n",
+		"b":      "key3\n\nkey4\n",
+		"..data": "ignored",
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+	}
+
+	values, err := ReadDir(dir)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if !reflect.DeepEqual(values, []string{"key1", "key2", "key3", "key4"}) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
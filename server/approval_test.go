@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/data-preservation-programs/filsigner-relayed/approval"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/ipfs/go-cid"
+	"github.com/jsign/go-filsigner/wallet"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"path/filepath"
+	"testing"
+)
+
+const testWalletKey = "7b2254797065223a22736563703235366b31222c22507269766174654b6579223a2244485a65316e7146756c7142382b44345a6167566f4f6654566d366e6f45415076414431705051446167343d227d"
+
+// testResolver resolves every key address to the same ID address, or fails with err
+type testResolver struct {
+	err error
+}
+
+func (r *testResolver) LookupID(_ context.Context, addr address.Address) (address.Address, error) {
+	if r.err != nil {
+		return address.Undef, r.err
+	}
+	if addr.Protocol() == address.ID {
+		return addr, nil
+	}
+
+	return address.NewIDAddress(1000)
+}
+
+func (r *testResolver) AccountKey(_ context.Context, addr address.Address) (address.Address, error) {
+	return addr, r.err
+}
+
+// newTestServer creates a server holding the test wallet key through NewServer, resolving every wallet to f01000
+func newTestServer(t *testing.T, options ...Option) (*Server, address.Address) {
+	t.Helper()
+	address.CurrentNetwork = address.Mainnet
+	clientAddr, err := wallet.PublicKey(testWalletKey)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	identityKey, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	server, err := NewServer(identityKey, nil, []string{testWalletKey}, nil, append([]Option{WithResolver(&testResolver{})}, options...)...)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	t.Cleanup(func() { server.host.Close() })
+
+	return server, clientAddr
+}
+
+func testProposal(t *testing.T, clientAddr address.Address) []byte {
+	t.Helper()
+	proposal := filmarket.DealProposal{
+		PieceCID:             cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+		PieceSize:            256,
+		VerifiedDeal:         true,
+		Client:               clientAddr,
+		Provider:             address.TestAddress,
+		Label:                filmarket.EmptyDealLabel,
+		StoragePricePerEpoch: big.Zero(),
+		ProviderCollateral:   big.Zero(),
+		ClientCollateral:     big.Zero(),
+	}
+	proposalBytes, err := cborutil.Dump(&proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	return proposalBytes
+}
+
+func TestApprovalQueue(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := approval.NewQueue(filepath.Join(dir, "approvals"))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	auditPath := filepath.Join(dir, "audit.log")
+	auditLog, err := audit.Open(auditPath)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	defer auditLog.Close()
+
+	server, clientAddr := newTestServer(t,
+		WithAuditLog(auditLog, auditPath),
+		WithApprovalQueue(queue, approval.Rules{PieceSizeAbove: abi.PaddedPieceSize(128)}))
+	request := testProposal(t, clientAddr)
+
+	response := server.signProposal("requester", request)
+	if response.Code != model.PendingApproval || response.Ticket == "" {
+		t.Fatalf("expected pending approval with ticket, got %v", response)
+	}
+
+	ticket, err := server.ApproveTicket(response.Ticket, "looks good")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	valid, err := wallet.WalletVerify(clientAddr, request, append([]byte{}, ticket.Signature...))
+	if err != nil || !valid {
+		t.Fatalf("approved signature is not valid: %v", err)
+	}
+
+	response = server.signProposal("requester", request)
+	if response.Code != model.Success || string(response.Signature) != string(ticket.Signature) {
+		t.Fatalf("expected approved signature, got %v", response)
+	}
+
+	_, err = server.RejectTicket(ticket.ID, "")
+	if err == nil {
+		t.Fatalf("expected decided ticket to not be rejected")
+	}
+
+	var events []audit.Event
+	err = audit.Read(auditPath, func(record audit.Record) error {
+		events = append(events, record.Event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if len(events) != 2 || events[0] != audit.PendingApproval || events[1] != audit.Approved {
+		t.Fatalf("unexpected audit events: %v", events)
+	}
+}
+
+func TestApprovalRejected(t *testing.T) {
+	queue, err := approval.NewQueue(t.TempDir())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	server, clientAddr := newTestServer(t, WithApprovalQueue(queue, approval.Rules{NewProviders: true}))
+	request := testProposal(t, clientAddr)
+
+	response := server.signProposal("requester", request)
+	if response.Code != model.PendingApproval {
+		t.Fatalf("expected pending approval, got %v", response)
+	}
+
+	_, err = server.RejectTicket(response.Ticket, "unknown provider")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	response = server.signProposal("requester", request)
+	if response.Code != model.ApprovalRejected {
+		t.Fatalf("expected approval rejected, got %v", response)
+	}
+}
+
+func TestApprovalActorsVersion(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := approval.NewQueue(filepath.Join(dir, "approvals"))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	auditPath := filepath.Join(dir, "audit.log")
+	auditLog, err := audit.Open(auditPath)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	defer auditLog.Close()
+
+	server, clientAddr := newTestServer(t,
+		WithAuditLog(auditLog, auditPath),
+		WithNetworkVersion(network.Version17),
+		WithApprovalQueue(queue, approval.Rules{NewProviders: true}))
+	request := &model.SignRequest{
+		Type:           model.MessageTypeDealProposal,
+		Payload:        testProposal(t, clientAddr),
+		NetworkVersion: uint64(network.Version18),
+	}
+
+	response := server.signRequest("requester", signRequestBytes(t, request))
+	if response.Code != model.PendingApproval {
+		t.Fatalf("expected pending approval, got %v", response)
+	}
+
+	// The ticket keeps the actors version of the request, not the one of the server
+	ticket, err := server.ApproveTicket(response.Ticket, "")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if ticket.ActorsVersion != 10 {
+		t.Fatalf("unexpected ticket actors version %d", ticket.ActorsVersion)
+	}
+
+	versions := make(map[audit.Event]int)
+	err = audit.Read(auditPath, func(record audit.Record) error {
+		versions[record.Event] = record.ActorsVersion
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if versions[audit.PendingApproval] != 10 || versions[audit.Approved] != 10 {
+		t.Fatalf("unexpected actors versions: %v", versions)
+	}
+}
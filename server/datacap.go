@@ -0,0 +1,131 @@
+package server
+
+import (
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/datacap"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/filecoin-project/go-address"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+var (
+	signedDataCap = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "filsigner",
+		Name:      "datacap_signed_bytes_total",
+		Help:      "The padded piece size of the verified proposals signed",
+	}, []string{"wallet", "requester", "provider"})
+	remainingDataCap = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "filsigner",
+		Name:      "datacap_budget_remaining_bytes",
+		Help:      "The datacap left in a budget of a wallet, for the requester or provider of the budget scope",
+	}, []string{"wallet", "budget", "scope"})
+)
+
+func init() {
+	prometheus.MustRegister(signedDataCap, remainingDataCap)
+}
+
+// WithDataCapBudgets limits the verified piece size signed for the wallets within rolling windows
+func WithDataCapBudgets(budgets []datacap.Budget) Option {
+	return func(s *Server) error {
+		s.budgets = budgets
+		var window time.Duration
+		for _, budget := range budgets {
+			if budget.Window > window {
+				window = budget.Window
+			}
+		}
+
+		s.ledger.Keep(window)
+		return nil
+	}
+}
+
+// walletLabel returns the key address of the wallet for the metric labels, so that the metrics of a wallet
+// join whether the proposals use its ID or key address
+func (s *Server) walletLabel(wallet string) string {
+	addr, err := address.NewFromString(wallet)
+	if err != nil {
+		return wallet
+	}
+
+	key, ok := s.keys()[addr]
+	if !ok {
+		return wallet
+	}
+
+	return key.String()
+}
+
+// accountDataCap adds the verified proposal of the record to the ledger.
+// Nothing is kept without budgets, as the ledger would never prune it.
+func (s *Server) accountDataCap(record audit.Record) {
+	if len(s.budgets) == 0 || !record.VerifiedDeal || record.PieceSize == 0 {
+		return
+	}
+
+	s.ledger.Add(datacap.Entry{
+		Time:      record.Time,
+		Wallet:    record.Client,
+		Requester: record.Requester,
+		Provider:  record.Provider,
+		Size:      record.PieceSize,
+	})
+}
+
+// reserveDataCap checks the verified proposal against the budgets of its client and accounts for it if it fits.
+// It returns the least remaining budget, and the function to release the datacap if the proposal is not signed after all.
+func (s *Server) reserveDataCap(requester string, proposal *filmarket.DealProposal) (*uint64, func(), model.StatusCode, error) {
+	if len(s.budgets) == 0 || !proposal.VerifiedDeal {
+		return nil, func() {}, model.Success, nil
+	}
+
+	keyMap := s.keys()
+	wallets := map[string]bool{proposal.Client.String(): true}
+	for addr, key := range keyMap {
+		if key == keyMap[proposal.Client] {
+			wallets[addr.String()] = true
+		}
+	}
+
+	entry := datacap.Entry{
+		Time:      time.Now(),
+		Wallet:    proposal.Client.String(),
+		Requester: requester,
+		Provider:  proposal.Provider.String(),
+		Size:      uint64(proposal.PieceSize),
+	}
+
+	usages, release, ok := s.ledger.Reserve(s.budgets, wallets, entry)
+	var least *uint64
+	var exceeded []string
+	for _, usage := range usages {
+		remaining := usage.Remaining
+		if !usage.Fits {
+			exceeded = append(exceeded, fmt.Sprintf("%s with %d bytes left", usage.Budget, remaining))
+		}
+
+		scope := ""
+		switch usage.Budget.Scope {
+		case datacap.ScopeRequester:
+			scope = requester
+		case datacap.ScopeProvider:
+			scope = entry.Provider
+		}
+		remainingDataCap.WithLabelValues(s.walletLabel(entry.Wallet), usage.Budget.String(), scope).Set(float64(remaining))
+
+		if least == nil || remaining < *least {
+			least = &remaining
+		}
+	}
+
+	if !ok {
+		return least, release, model.DataCapBudgetExceeded, errors.Errorf("piece size %d exceeds the datacap budget %s", entry.Size, exceeded[0])
+	}
+
+	return least, release, model.Success, nil
+}
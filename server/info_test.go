@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"github.com/data-preservation-programs/filsigner-relayed/chainmsg"
+	"github.com/data-preservation-programs/filsigner-relayed/config"
+	"github.com/data-preservation-programs/filsigner-relayed/cosign"
+	"github.com/data-preservation-programs/filsigner-relayed/datacap"
+	"github.com/data-preservation-programs/filsigner-relayed/epochwindow"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/removedatacap"
+	"github.com/data-preservation-programs/filsigner-relayed/replication"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/jsign/go-filsigner/wallet"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInfo(t *testing.T) {
+	server, clientAddr := newTestServer(t)
+	idAddr, err := address.NewIDAddress(1000)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	server.cosignPolicy = &cosign.Policy{Wallets: []address.Address{idAddr}}
+
+	info := server.info()
+	if info.Code != model.Success || len(info.Wallets) != 1 {
+		t.Fatalf("unexpected info: %v", info)
+	}
+
+	wallet := info.Wallets[0]
+	if wallet.Address != clientAddr.String() || wallet.IDAddress != "f01000" || !wallet.Cosign ||
+		len(wallet.MessageTypes) != 1 || wallet.MessageTypes[0].Name != model.MessageTypeDealProposal {
+		t.Fatalf("unexpected wallet info: %v", wallet)
+	}
+}
+
+func TestInfoPolicy(t *testing.T) {
+	clientAddr, err := wallet.PublicKey(testWalletKey)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	key, operator := newTestOperator(t)
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	writeTestManifest(t, key, manifestPath, "baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa,256\n")
+	server, _ := newTestServer(t,
+		WithChainMessageRules(map[address.Address][]chainmsg.Rule{
+			clientAddr: {{To: builtin.StorageMarketActorAddr, Method: 2, MaxValue: big.NewInt(1000)}},
+		}),
+		WithRemoveDataCapRules(map[address.Address][]removedatacap.Rule{
+			clientAddr: {{Client: builtin.VerifiedRegistryActorAddr}},
+		}),
+		WithNetwork(config.Mainnet),
+		WithEpochWindow(epochwindow.Window{MinLead: 10, MaxDuration: 100}),
+		WithDataCapBudgets([]datacap.Budget{{Scope: datacap.ScopeRequester, Window: 24 * time.Hour, Limit: 1 << 20}}),
+		WithReplicationLimits(replication.Limits{MaxReplicas: 5, MaxPerGroup: 2}),
+		WithPieceManifest(manifestPath, []peer.ID{operator}))
+
+	policy := server.info().Policy
+	if len(policy.ChainMessageRules) != 1 || policy.ChainMessageRules[0].Wallet != clientAddr.String() ||
+		policy.ChainMessageRules[0].Rule != "f05:2:1000:70000000000000000" {
+		t.Fatalf("unexpected chain message rules: %v", policy.ChainMessageRules)
+	}
+	if len(policy.RemoveDataCapRules) != 1 || policy.RemoveDataCapRules[0].Rule != "f06" {
+		t.Fatalf("unexpected remove datacap rules: %v", policy.RemoveDataCapRules)
+	}
+	if policy.StartMinLead != 10 || policy.StartMaxLead != 0 || policy.MaxDuration != 100 {
+		t.Fatalf("unexpected epoch window: %v", policy)
+	}
+	if len(policy.DataCapBudgets) != 1 || policy.DataCapBudgets[0] != (model.BudgetInfo{Scope: "requester", Window: 86400, Limit: 1 << 20}) {
+		t.Fatalf("unexpected datacap budgets: %v", policy.DataCapBudgets)
+	}
+	if policy.MaxReplicas != 5 || policy.MaxReplicasPerProvider != 0 || policy.MaxReplicasPerGroup != 2 {
+		t.Fatalf("unexpected replication limits: %v", policy)
+	}
+	if policy.ManifestVersion != server.pieceManifest().Version {
+		t.Fatalf("unexpected manifest version: %s", policy.ManifestVersion)
+	}
+
+	// The policy round-trips through the info protocol encoding
+	buf := new(bytes.Buffer)
+	err = policy.MarshalCBOR(buf)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	decoded := model.PolicyInfo{}
+	err = decoded.UnmarshalCBOR(buf)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, policy) {
+		t.Fatalf("unexpected decoded policy: %v", decoded)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/manifest"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+)
+
+// WithPieceManifest only signs proposals for the pieces of the manifest at path, which must be signed by one of the operators.
+// The manifest is read again when the server reloads.
+func WithPieceManifest(path string, operators []peer.ID) Option {
+	return func(s *Server) error {
+		if len(operators) == 0 {
+			return errors.New("a piece manifest requires at least one operator key")
+		}
+
+		s.manifestPath = path
+		s.manifestOperators = operators
+		loaded, err := manifest.Load(path, operators)
+		if err != nil {
+			return errors.Wrap(err, "failed to load piece manifest")
+		}
+
+		s.manifest = loaded
+		return nil
+	}
+}
+
+func (s *Server) pieceManifest() *manifest.Manifest {
+	s.manifestMu.RLock()
+	defer s.manifestMu.RUnlock()
+	return s.manifest
+}
+
+// reloadManifest reads the piece manifest again and records its version if it changed.
+// The previous manifest is kept if the new one cannot be verified.
+func (s *Server) reloadManifest() error {
+	if s.manifestPath == "" {
+		return nil
+	}
+
+	loaded, err := manifest.Load(s.manifestPath, s.manifestOperators)
+	if err != nil {
+		return errors.Wrap(err, "failed to reload piece manifest")
+	}
+
+	s.manifestMu.Lock()
+	previous := s.manifest
+	s.manifest = loaded
+	s.manifestMu.Unlock()
+	if previous == nil || previous.Version != loaded.Version {
+		s.recordManifest(loaded)
+	}
+
+	return nil
+}
+
+// recordManifest records the version of the piece manifest the signer checks proposals against from now on
+func (s *Server) recordManifest(loaded *manifest.Manifest) {
+	logging.Logger("server").Infow("loaded piece manifest", "version", loaded.Version, "pieces", loaded.Len())
+	s.record(audit.Record{
+		Event:           audit.ManifestLoaded,
+		Message:         fmt.Sprintf("%d pieces", loaded.Len()),
+		ManifestVersion: loaded.Version,
+	})
+}
+
+// checkManifest checks that the piece of the proposal is in the piece manifest, if there is one,
+// and returns the version of the manifest it was checked against
+func (s *Server) checkManifest(proposal *filmarket.DealProposal) (string, model.StatusCode, error) {
+	current := s.pieceManifest()
+	if current == nil {
+		return "", model.Success, nil
+	}
+
+	err := current.Check(proposal.PieceCID, proposal.PieceSize)
+	if err != nil {
+		return current.Version, model.PieceNotInManifest, err
+	}
+
+	return current.Version, model.Success, nil
+}
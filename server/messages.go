@@ -0,0 +1,309 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/chainmsg"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/data-preservation-programs/filsigner-relayed/message"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	filnetwork "github.com/filecoin-project/go-state-types/network"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"io"
+)
+
+// messageType decodes and validates the payloads of a message type other than deal proposals,
+// which go through the proposal pipeline instead
+type messageType struct {
+	// meta is the message type told to remote wallets
+	meta keystore.MsgType
+	// validate checks the payload before it is signed for the wallet
+	validate func(s *Server, requester peer.ID, wallet address.Address, payload []byte) (model.StatusCode, error)
+	// signed builds the signed payload returned along with the signature of the key, if the message type has one
+	signed func(key address.Address, payload []byte, signature *filcrypto.Signature) ([]byte, error)
+	// describe adds the details of the payload to the audit record of the signature
+	describe func(record *audit.Record, payload []byte)
+}
+
+var messageTypes = map[string]messageType{
+	// Raw messages are prefixed before signing, so any payload is fine
+	model.MessageTypeRaw: {
+		meta: keystore.MTUnknown,
+		validate: func(*Server, peer.ID, address.Address, []byte) (model.StatusCode, error) {
+			return model.Success, nil
+		},
+	},
+	model.MessageTypeDealStatusRequest: {
+		meta:     keystore.MTUnknown,
+		validate: validateDealStatusRequest,
+	},
+	model.MessageTypeChainMessage: {
+		meta:     keystore.MTChainMsg,
+		validate: validateChainMessage,
+		signed:   signedChainMessage,
+	},
+	model.MessageTypeRemoveDataCapProposal: {
+		meta:     keystore.MTUnknown,
+		validate: validateRemoveDataCap,
+		signed:   signedRemoveDataCap,
+		describe: describeRemoveDataCap,
+	},
+}
+
+// validateChainMessage checks that the message is sent from the wallet and allowed by the chain message rules of the wallet
+func validateChainMessage(s *Server, _ peer.ID, wallet address.Address, payload []byte) (model.StatusCode, error) {
+	msg, err := chainmsg.Decode(payload)
+	if err != nil {
+		return model.DecodeRequestError, err
+	}
+
+	keyMap := s.keys()
+	if keyMap[msg.From] != keyMap[wallet] {
+		return model.ChainMessageNotAllowed, errors.Errorf("message is sent from %s, not from the signing wallet %s", msg.From, wallet)
+	}
+
+	rules, _ := walletEntries(keyMap, s.chainRules, wallet)
+	err = chainmsg.Check(rules, msg)
+	if err != nil {
+		return model.ChainMessageNotAllowed, err
+	}
+
+	return model.Success, nil
+}
+
+func signedChainMessage(_ address.Address, payload []byte, signature *filcrypto.Signature) ([]byte, error) {
+	msg, err := chainmsg.Decode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return cborutil.Dump(&chainmsg.SignedMessage{Message: *msg, Signature: *signature})
+}
+
+// validateDealStatusRequest checks that the deal of the UUID was signed for the wallet, if the audit trail is kept
+func validateDealStatusRequest(s *Server, _ peer.ID, wallet address.Address, payload []byte) (model.StatusCode, error) {
+	if len(payload) != 16 {
+		return model.DecodeRequestError, errors.Errorf("deal status request must be a 16 byte uuid, got %d bytes", len(payload))
+	}
+
+	if s.audit == nil {
+		return model.Success, nil
+	}
+
+	var uuid [16]byte
+	copy(uuid[:], payload)
+	dealUUID := message.FormatUUID(uuid)
+	s.dealsMu.Lock()
+	client, ok := s.signedDeals[dealUUID]
+	s.dealsMu.Unlock()
+	if !ok {
+		return model.UnknownDeal, errors.Errorf("no proposal was signed for deal %s", dealUUID)
+	}
+
+	clientAddr, err := address.NewFromString(client)
+	keyMap := s.keys()
+	if err != nil || keyMap[clientAddr] != keyMap[wallet] {
+		return model.UnknownDeal, errors.Errorf("deal %s was signed for another client", dealUUID)
+	}
+
+	return model.Success, nil
+}
+
+// WithMessageTypes sets the message types each wallet signs. Wallets that are not listed only sign deal proposals.
+func WithMessageTypes(allowed map[address.Address][]string) Option {
+	return func(s *Server) error {
+		for wallet, types := range allowed {
+			for _, msgType := range types {
+				if _, ok := messageTypes[msgType]; !ok && msgType != model.MessageTypeDealProposal {
+					return errors.Errorf("unsupported message type %s for wallet %s", msgType, wallet)
+				}
+			}
+		}
+
+		s.messageTypes = allowed
+		return nil
+	}
+}
+
+// WithChainMessageRules sets the recipients, methods and value caps of the chain messages each wallet signs.
+// Wallets without rules sign no chain messages.
+func WithChainMessageRules(rules map[address.Address][]chainmsg.Rule) Option {
+	return func(s *Server) error {
+		s.chainRules = rules
+		return nil
+	}
+}
+
+// walletEntries collects the entries configured for the wallet by any of its addresses, and reports whether there are any
+func walletEntries[T any](keyMap map[address.Address]address.Address, configured map[address.Address][]T, wallet address.Address) ([]T, bool) {
+	key, hasKey := keyMap[wallet]
+	found := false
+	var entries []T
+	for addr, addrEntries := range configured {
+		if addr != wallet && (!hasKey || keyMap[addr] != key) {
+			continue
+		}
+
+		found = true
+		entries = append(entries, addrEntries...)
+	}
+
+	return entries, found
+}
+
+// allowedMessageTypes returns the message types the wallet signs, by any of its addresses
+func (s *Server) allowedMessageTypes(wallet address.Address) []string {
+	types, configured := walletEntries(s.keys(), s.messageTypes, wallet)
+	if !configured {
+		return []string{model.MessageTypeDealProposal}
+	}
+
+	return types
+}
+
+func (s *Server) messageTypeAllowed(wallet address.Address, msgType string) bool {
+	for _, allowed := range s.allowedMessageTypes(wallet) {
+		if allowed == msgType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signRequest runs the signing pipeline of the message type for a request of the sign protocol
+func (s *Server) signRequest(requester peer.ID, data []byte) *model.SignerResponse {
+	request := new(model.SignRequest)
+	err := request.UnmarshalCBOR(bytes.NewReader(data))
+	if err != nil {
+		return s.reject(requester, nil, model.DecodeRequestError, err.Error())
+	}
+
+	if request.Type == model.MessageTypeDealProposal {
+		return s.signRequestProposal(requester, request)
+	}
+
+	wallet, err := address.NewFromString(request.Wallet)
+	if err != nil {
+		return s.reject(requester, nil, model.DecodeRequestError, "failed to decode wallet address: "+err.Error())
+	}
+
+	record := messageRecord(audit.Rejected, requester, wallet, request.Type)
+	msgType, ok := messageTypes[request.Type]
+	if !ok {
+		return s.rejectRecord(record, model.MessageTypeNotAllowed, "unsupported message type "+request.Type)
+	}
+
+	keyAddr, ok := s.keys()[wallet]
+	if !ok {
+		return s.rejectRecord(record, model.WalletKeyNotFound, "private key not found for the address "+wallet.String())
+	}
+
+	if !s.messageTypeAllowed(wallet, request.Type) {
+		return s.rejectRecord(record, model.MessageTypeNotAllowed, "wallet "+wallet.String()+" does not sign "+request.Type+" messages")
+	}
+
+	if s.cosignRequired(wallet) {
+		return s.rejectRecord(record, model.CosignatureRequired, "wallets under the two-person rule only sign deal proposals")
+	}
+
+	code, err := msgType.validate(s, requester, wallet, request.Payload)
+	if err != nil {
+		return s.rejectRecord(record, code, err.Error())
+	}
+
+	signingBytes, err := message.SigningBytes(request.Type, request.Payload)
+	if err != nil {
+		return s.rejectRecord(record, model.EncodeRequestError, err.Error())
+	}
+
+	// Remote wallets get the payload to check what they sign, such as the message behind a chain message CID
+	meta := keystore.MsgMeta{Type: msgType.meta, Extra: request.Payload}
+	signature, err := s.keystore.Sign(context.Background(), keyAddr, signingBytes, meta)
+	if err != nil {
+		return s.rejectRecord(record, model.WalletSignError, err.Error())
+	}
+
+	signatureBytes, err := signature.MarshalBinary()
+	if err != nil {
+		return s.rejectRecord(record, model.MarshalSignatureError, err.Error())
+	}
+
+	response := &model.SignerResponse{
+		Code:      model.Success,
+		Signature: signatureBytes,
+	}
+	if msgType.signed != nil {
+		response.Signed, err = msgType.signed(keyAddr, request.Payload, signature)
+		if err != nil {
+			return s.rejectRecord(record, model.EncodeResponseError, err.Error())
+		}
+	}
+
+	record = messageRecord(audit.Signed, requester, wallet, request.Type)
+	if msgType.describe != nil {
+		msgType.describe(&record, request.Payload)
+	}
+
+	s.record(record)
+	return response
+}
+
+// signRequestProposal signs a deal proposal of the sign protocol, which must be from the wallet of the request if it is set
+func (s *Server) signRequestProposal(requester peer.ID, request *model.SignRequest) *model.SignerResponse {
+	// The UUID is kept in the canonical form the deal status requests are looked up with
+	dealUUID := ""
+	if request.DealUUID != "" {
+		uuid, err := message.ParseUUID(request.DealUUID)
+		if err != nil {
+			return s.reject(requester, nil, model.DecodeRequestError, err.Error())
+		}
+		dealUUID = message.FormatUUID(uuid)
+	}
+
+	if request.Wallet == "" {
+		return s.signDealProposal(requester, request.Payload, dealUUID, filnetwork.Version(request.NetworkVersion))
+	}
+
+	wallet, err := address.NewFromString(request.Wallet)
+	if err != nil {
+		return s.reject(requester, nil, model.DecodeRequestError, "failed to decode wallet address: "+err.Error())
+	}
+
+	keyMap := s.keys()
+	// Undecodable proposals are rejected by signDealProposal
+	proposal := s.decodeProposal(request.Payload, filnetwork.Version(request.NetworkVersion))
+	if proposal != nil && keyMap[proposal.Client] != keyMap[wallet] {
+		return s.reject(requester, proposal, model.WalletKeyNotFound,
+			"proposal client "+proposal.Client.String()+" is not the signing wallet "+wallet.String())
+	}
+
+	return s.signDealProposal(requester, request.Payload, dealUUID, filnetwork.Version(request.NetworkVersion))
+}
+
+func (s *Server) handleSign(stream network.Stream) {
+	log := logging.Logger("server").With("remote", stream.Conn().RemotePeer().String())
+	log.Info("got sign request")
+	defer stream.Close()
+
+	requester := stream.Conn().RemotePeer()
+	if !s.isAllowed(requester) {
+		SendError(stream, model.UnauthorizedRequester, "request is not from allowed requesters")
+		return
+	}
+
+	request, err := io.ReadAll(stream)
+	if err != nil {
+		writeResponse(stream, s.reject(requester, nil, model.ReadStreamError, err.Error()))
+		return
+	}
+
+	writeResponse(stream, s.signRequest(requester, request))
+}
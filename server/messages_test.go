@@ -0,0 +1,319 @@
+package server
+
+import (
+	"bytes"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/chainmsg"
+	"github.com/data-preservation-programs/filsigner-relayed/message"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/removedatacap"
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin/v9/verifreg"
+	"github.com/filecoin-project/go-state-types/network"
+	"github.com/jsign/go-filsigner/wallet"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"path/filepath"
+	"testing"
+)
+
+func signRequestBytes(t *testing.T, request *model.SignRequest) []byte {
+	t.Helper()
+	requestBytes, err := cborutil.Dump(request)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	return requestBytes
+}
+
+func TestSignRequest(t *testing.T) {
+	server, clientAddr := newTestServer(t)
+	requester := peer.ID("requester")
+	raw := &model.SignRequest{Type: model.MessageTypeRaw, Wallet: clientAddr.String(), Payload: []byte("hello")}
+
+	// Wallets only sign deal proposals unless configured otherwise
+	response := server.signRequest(requester, signRequestBytes(t, raw))
+	if response.Code != model.MessageTypeNotAllowed {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	proposal := &model.SignRequest{Type: model.MessageTypeDealProposal, Payload: testProposal(t, clientAddr)}
+	response = server.signRequest(requester, signRequestBytes(t, proposal))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	proposal.Wallet = address.TestAddress.String()
+	response = server.signRequest(requester, signRequestBytes(t, proposal))
+	if response.Code != model.WalletKeyNotFound {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	err := WithMessageTypes(map[address.Address][]string{clientAddr: {model.MessageTypeRaw}})(server)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	response = server.signRequest(requester, signRequestBytes(t, raw))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	valid, err := wallet.WalletVerify(clientAddr, message.Raw(raw.Payload), response.Signature)
+	if err != nil || !valid {
+		t.Fatalf("signature is not valid: %v", err)
+	}
+
+	// The allowlist replaces the default, so deal proposals are no longer signed for the wallet
+	response = server.signProposal(requester, testProposal(t, clientAddr))
+	if response.Code != model.MessageTypeNotAllowed {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	raw.Type = "BlockHeader"
+	response = server.signRequest(requester, signRequestBytes(t, raw))
+	if response.Code != model.MessageTypeNotAllowed {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	err = WithMessageTypes(map[address.Address][]string{clientAddr: {"BlockHeader"}})(server)
+	if err == nil {
+		t.Fatal("expected an error for an unsupported message type")
+	}
+}
+
+func TestDealStatusRequest(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(auditPath)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	defer auditLog.Close()
+
+	server, clientAddr := newTestServer(t, WithAuditLog(auditLog, auditPath))
+	server.messageTypes = map[address.Address][]string{clientAddr: {model.MessageTypeDealProposal, model.MessageTypeDealStatusRequest}}
+	requester := peer.ID("requester")
+	dealUUID, err := message.ParseUUID("0b2a7d9e-4c1f-4e8a-9f3b-6d5c2a1e0f47")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	status := &model.SignRequest{Type: model.MessageTypeDealStatusRequest, Wallet: clientAddr.String(), Payload: dealUUID[:]}
+	response := server.signRequest(requester, signRequestBytes(t, status))
+	if response.Code != model.UnknownDeal {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	proposal := &model.SignRequest{
+		Type:     model.MessageTypeDealProposal,
+		Payload:  testProposal(t, clientAddr),
+		DealUUID: "0B2A7D9E-4C1F-4E8A-9F3B-6D5C2A1E0F47",
+	}
+	response = server.signRequest(requester, signRequestBytes(t, proposal))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	response = server.signRequest(requester, signRequestBytes(t, status))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	valid, err := wallet.WalletVerify(clientAddr, dealUUID[:], response.Signature)
+	if err != nil || !valid {
+		t.Fatalf("signature is not valid: %v", err)
+	}
+
+	// Deals signed in previous runs are loaded from the audit trail
+	restarted, _ := newTestServer(t, WithAuditLog(auditLog, auditPath))
+	restarted.messageTypes = map[address.Address][]string{clientAddr: {model.MessageTypeDealStatusRequest}}
+	response = restarted.signRequest(requester, signRequestBytes(t, status))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+}
+
+func TestChainMessage(t *testing.T) {
+	server, clientAddr := newTestServer(t)
+	market, err := address.NewIDAddress(5)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	server.messageTypes = map[address.Address][]string{clientAddr: {model.MessageTypeChainMessage}}
+	server.chainRules = map[address.Address][]chainmsg.Rule{clientAddr: {{To: market, Method: 2, MaxValue: big.NewInt(1000)}}}
+	requester := peer.ID("requester")
+	msg := &chainmsg.Message{
+		To:         market,
+		From:       clientAddr,
+		Nonce:      3,
+		Value:      big.NewInt(1000),
+		GasLimit:   1000000,
+		GasFeeCap:  big.NewInt(100),
+		GasPremium: big.NewInt(10),
+		Method:     2,
+	}
+	msgBytes, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	request := &model.SignRequest{Type: model.MessageTypeChainMessage, Wallet: clientAddr.String(), Payload: msgBytes}
+	response := server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	signed := new(chainmsg.SignedMessage)
+	err = signed.UnmarshalCBOR(bytes.NewReader(response.Signed))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	msgCid, err := msg.Cid()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	valid, err := wallet.WalletVerify(clientAddr, msgCid.Bytes(), response.Signature)
+	if err != nil || !valid || signed.Message.Nonce != 3 {
+		t.Fatalf("signed message is not valid: %v", err)
+	}
+
+	msg.Value = big.NewInt(1001)
+	request.Payload, err = msg.Bytes()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	response = server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.ChainMessageNotAllowed {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	// A message without value can still not spend more than the max fee in gas
+	msg.Value = big.Zero()
+	msg.GasFeeCap = chainmsg.DefaultMaxFee
+	request.Payload, err = msg.Bytes()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	response = server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.ChainMessageNotAllowed {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	msg.GasFeeCap = big.NewInt(100)
+	msg.From = address.TestAddress
+	request.Payload, err = msg.Bytes()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	response = server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.ChainMessageNotAllowed {
+		t.Fatalf("unexpected response: %v", response)
+	}
+}
+
+func TestRemoveDataCap(t *testing.T) {
+	server, clientAddr := newTestServer(t)
+	verifiedClient, err := address.NewIDAddress(1234)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	server.messageTypes = map[address.Address][]string{clientAddr: {model.MessageTypeRemoveDataCapProposal}}
+	server.removalRules = map[address.Address][]removedatacap.Rule{clientAddr: {{Client: verifiedClient, MaxAmount: big.NewInt(1 << 20)}}}
+	requester := peer.ID("requester")
+	proposal := &verifreg.RemoveDataCapProposal{
+		VerifiedClient:    verifiedClient,
+		DataCapAmount:     big.NewInt(1 << 20),
+		RemovalProposalID: verifreg.RmDcProposalID{ProposalID: 2},
+	}
+	request := &model.SignRequest{Type: model.MessageTypeRemoveDataCapProposal, Wallet: clientAddr.String()}
+	request.Payload, err = cborutil.Dump(proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	response := server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	removal := new(verifreg.RemoveDataCapRequest)
+	err = removal.UnmarshalCBOR(bytes.NewReader(response.Signed))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	valid, err := wallet.WalletVerify(clientAddr, removedatacap.SigningBytes(request.Payload), response.Signature)
+	if err != nil || !valid || removal.Verifier != clientAddr {
+		t.Fatalf("remove datacap request is not valid: %v", err)
+	}
+
+	proposal.RemovalProposalID.ProposalID = 1
+	request.Payload, err = cborutil.Dump(proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	response = server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.DataCapRemovalNotAllowed {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	proposal.RemovalProposalID.ProposalID = 3
+	proposal.DataCapAmount = big.NewInt(1<<20 + 1)
+	request.Payload, err = cborutil.Dump(proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	response = server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.DataCapRemovalNotAllowed {
+		t.Fatalf("unexpected response: %v", response)
+	}
+}
+
+func TestProposalNetworkVersion(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(auditPath)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	defer auditLog.Close()
+
+	server, clientAddr := newTestServer(t, WithAuditLog(auditLog, auditPath), WithNetworkVersion(network.Version17))
+	requester := peer.ID("requester")
+	request := &model.SignRequest{Type: model.MessageTypeDealProposal, Payload: testProposal(t, clientAddr)}
+	response := server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	request.NetworkVersion = uint64(network.Version18)
+	response = server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	request.NetworkVersion = uint64(network.Version15)
+	response = server.signRequest(requester, signRequestBytes(t, request))
+	if response.Code != model.DecodeRequestError {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	var versions []int
+	err = audit.Read(auditPath, func(record audit.Record) error {
+		if record.Event == audit.Signed {
+			versions = append(versions, record.ActorsVersion)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if len(versions) != 2 || versions[0] != 9 || versions[1] != 10 {
+		t.Fatalf("unexpected actors versions: %v", versions)
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"bytes"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"testing"
+)
+
+func TestRemarshalMismatch(t *testing.T) {
+	server, clientAddr := newTestServer(t)
+	proposalBytes := testProposal(t, clientAddr)
+
+	// The client collateral is the last field, encode its zero value with a zero magnitude byte instead of as empty bytes
+	offset := len(proposalBytes) - 1
+	request := append(append([]byte{}, proposalBytes[:offset]...), 0x42, 0x00, 0x00)
+
+	response := server.signProposal(peer.ID("requester"), request)
+	if response.Code != model.ProposalRemarshalMismatch || response.Mismatch == nil {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	mismatch := response.Mismatch
+	if mismatch.Field != "ClientCollateral" || mismatch.Offset != uint64(offset) ||
+		!bytes.Equal(mismatch.Original, []byte{0x42, 0x00, 0x00}) || !bytes.Equal(mismatch.Remarshalled, []byte{0x40}) {
+		t.Fatalf("unexpected mismatch: %v", mismatch)
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/client"
+	"github.com/data-preservation-programs/filsigner-relayed/config"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/relay"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRelayedTestServer starts the server behind an in-process relay and returns a client allowed to use it
+func newRelayedTestServer(t *testing.T, options ...Option) (*Server, *client.Client, address.Address) {
+	t.Helper()
+	relayHost, err := libp2p.New(libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"), libp2p.EnableRelayService())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	t.Cleanup(func() { relayHost.Close() })
+
+	_, err = relay.New(relayHost, relay.WithInfiniteLimits())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	relays := []peer.AddrInfo{{ID: relayHost.ID(), Addrs: relayHost.Addrs()}}
+	clientKey, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	clientID, err := peer.IDFromPrivateKey(clientKey)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	server, clientAddr := newTestServer(t, options...)
+	server.relays = relays
+	server.allowedRequesters = []peer.ID{clientID}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go server.Start(ctx)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for server.activeReservations() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not make a relay reservation")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	signerClient, err := client.NewClient(clientKey, relays)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	return server, signerClient, clientAddr
+}
+
+func TestRelayedSignInfoAndPing(t *testing.T) {
+	server, signerClient, clientAddr := newRelayedTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	status, _, err := signerClient.Ping(ctx, server.host.ID())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if status.Reservations != 1 || !status.KeystoreUnlocked {
+		t.Fatalf("unexpected ping status: %v", status)
+	}
+
+	results := signerClient.PingRelays(ctx, server.host.ID(), 5*time.Second)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected relay ping results: %v", results)
+	}
+
+	info, err := signerClient.Info(ctx, server.host.ID())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if len(info.Wallets) != 1 || info.Wallets[0].Address != clientAddr.String() {
+		t.Fatalf("unexpected info: %v", info)
+	}
+
+	proposal := filmarket.DealProposal{
+		PieceCID:             cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+		PieceSize:            256,
+		Client:               clientAddr,
+		Provider:             clientAddr,
+		Label:                filmarket.EmptyDealLabel,
+		StoragePricePerEpoch: big.Zero(),
+		ProviderCollateral:   big.Zero(),
+		ClientCollateral:     big.Zero(),
+	}
+	_, err = signerClient.SignProposal(ctx, server.host.ID(), proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+}
+
+func TestRelayedPingCalibnet(t *testing.T) {
+	server, signerClient, _ := newRelayedTestServer(t, WithNetwork(config.Calibnet))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// The mainnet protocol IDs are not served on calibnet
+	_, _, err := signerClient.Ping(ctx, server.host.ID())
+	if err == nil {
+		t.Fatalf("expected mainnet ping to fail")
+	}
+
+	signerClient.Network = config.Calibnet
+	status, _, err := signerClient.Ping(ctx, server.host.ID())
+	if err != nil || status.Reservations != 1 {
+		t.Fatalf("unexpected ping status: %v %v", status, err)
+	}
+
+	results := signerClient.PingRelays(ctx, server.host.ID(), 5*time.Second)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected relay ping results: %v", results)
+	}
+}
+
+func TestRelayedUnauthorizedNotRecorded(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(auditPath)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	defer auditLog.Close()
+
+	server, signerClient, clientAddr := newRelayedTestServer(t, WithAuditLog(auditLog, auditPath))
+	server.allowedRequesters = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	proposal := filmarket.DealProposal{
+		PieceCID:             cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+		PieceSize:            256,
+		Client:               clientAddr,
+		Provider:             clientAddr,
+		Label:                filmarket.EmptyDealLabel,
+		StoragePricePerEpoch: big.Zero(),
+		ProviderCollateral:   big.Zero(),
+		ClientCollateral:     big.Zero(),
+	}
+	_, err = signerClient.SignProposal(ctx, server.host.ID(), proposal)
+	if err == nil || !strings.Contains(err.Error(), "UnauthorizedRequester") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = signerClient.SignMessage(ctx, server.host.ID(), model.MessageTypeRaw, clientAddr, []byte("hello"))
+	if err == nil || !strings.Contains(err.Error(), "UnauthorizedRequester") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = server.WalletSign(ctx, "requester", clientAddr, make([]byte, 16), keystore.MsgMeta{Type: keystore.MTUnknown})
+	if err == nil || !strings.Contains(err.Error(), "UnauthorizedRequester") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Requests from peers that are not allowed are not written to the audit log
+	err = audit.Read(auditPath, func(record audit.Record) error {
+		t.Fatalf("unexpected audit record: %v", record)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+}
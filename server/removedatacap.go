@@ -0,0 +1,106 @@
+package server
+
+import (
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/removedatacap"
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/filecoin-project/go-state-types/builtin/v9/verifreg"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+)
+
+// WithRemoveDataCapRules sets the verified clients each verifier wallet may sign datacap removals for.
+// Wallets without rules sign no removals.
+func WithRemoveDataCapRules(rules map[address.Address][]removedatacap.Rule) Option {
+	return func(s *Server) error {
+		s.removalRules = rules
+		return nil
+	}
+}
+
+func removalKey(verifier string, client string) string {
+	return verifier + "/" + client
+}
+
+// rememberRemoval keeps the latest proposal ID of the signed datacap removal of the record
+func (s *Server) rememberRemoval(record audit.Record) {
+	if record.MessageType != model.MessageTypeRemoveDataCapProposal || record.RemovalProposalID == nil {
+		return
+	}
+
+	key := removalKey(record.Client, record.VerifiedClient)
+	s.removalsMu.Lock()
+	defer s.removalsMu.Unlock()
+	if latest, ok := s.removalIDs[key]; !ok || *record.RemovalProposalID > latest {
+		s.removalIDs[key] = *record.RemovalProposalID
+	}
+}
+
+// latestRemoval returns the latest proposal ID signed by the verifier for the client, by any of the verifier addresses
+func (s *Server) latestRemoval(verifier address.Address, client address.Address) (uint64, bool) {
+	keyMap := s.keys()
+	s.removalsMu.Lock()
+	defer s.removalsMu.Unlock()
+	var latest uint64
+	found := false
+	for addr, key := range keyMap {
+		if key != keyMap[verifier] {
+			continue
+		}
+
+		id, ok := s.removalIDs[removalKey(addr.String(), client.String())]
+		if ok && (!found || id > latest) {
+			latest = id
+			found = true
+		}
+	}
+
+	return latest, found
+}
+
+// validateRemoveDataCap checks the proposal against the removal rules of the verifier wallet,
+// and that it is not older than a proposal already signed for the client
+func validateRemoveDataCap(s *Server, _ peer.ID, wallet address.Address, payload []byte) (model.StatusCode, error) {
+	proposal, err := removedatacap.Decode(payload)
+	if err != nil {
+		return model.DecodeRequestError, err
+	}
+
+	keyMap := s.keys()
+	if keyMap[proposal.VerifiedClient] == keyMap[wallet] {
+		return model.DataCapRemovalNotAllowed, errors.New("verifiers cannot remove their own datacap")
+	}
+
+	rules, _ := walletEntries(keyMap, s.removalRules, wallet)
+	err = removedatacap.Check(rules, proposal)
+	if err != nil {
+		return model.DataCapRemovalNotAllowed, err
+	}
+
+	latest, ok := s.latestRemoval(wallet, proposal.VerifiedClient)
+	if ok && proposal.RemovalProposalID.ProposalID < latest {
+		return model.DataCapRemovalNotAllowed, errors.Errorf("proposal id %d is older than the signed proposal id %d for %s",
+			proposal.RemovalProposalID.ProposalID, latest, proposal.VerifiedClient)
+	}
+
+	return model.Success, nil
+}
+
+// signedRemoveDataCap returns the RemoveDataCapRequest of the verifier, as RemoveVerifiedClientDataCap takes it
+func signedRemoveDataCap(key address.Address, _ []byte, signature *filcrypto.Signature) ([]byte, error) {
+	return cborutil.Dump(&verifreg.RemoveDataCapRequest{Verifier: key, VerifierSignature: *signature})
+}
+
+func describeRemoveDataCap(record *audit.Record, payload []byte) {
+	proposal, err := removedatacap.Decode(payload)
+	if err != nil {
+		return
+	}
+
+	id := proposal.RemovalProposalID.ProposalID
+	record.VerifiedClient = proposal.VerifiedClient.String()
+	record.RemovalProposalID = &id
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/replication"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+)
+
+var violationCodes = map[replication.Violation]model.StatusCode{
+	replication.TooManyReplicas:    model.ReplicaLimitExceeded,
+	replication.TooManyForProvider: model.ProviderReplicaLimitExceeded,
+	replication.TooManyForGroup:    model.ProviderGroupLimitExceeded,
+}
+
+// WithReplicationLimits limits the deals signed for each piece, in total, per provider and per provider group
+func WithReplicationLimits(limits replication.Limits) Option {
+	return func(s *Server) error {
+		s.replicationLimits = limits
+		return nil
+	}
+}
+
+// reserveReplica checks the proposal against the replication limits of its piece and accounts for it if it fits.
+// It returns the function to release the replica if the proposal is not signed after all.
+func (s *Server) reserveReplica(proposal *filmarket.DealProposal) (func(), model.StatusCode, error) {
+	if !s.replicationLimits.Enabled() {
+		return func() {}, model.Success, nil
+	}
+
+	piece := proposal.PieceCID.String()
+	violation, err := s.replicas.Reserve(s.replicationLimits, piece, proposal.Provider)
+	if err != nil {
+		return func() {}, violationCodes[violation], err
+	}
+
+	return func() { s.replicas.Remove(piece, proposal.Provider) }, model.Success, nil
+}
@@ -3,15 +3,30 @@ package server
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/approval"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/chain"
+	"github.com/data-preservation-programs/filsigner-relayed/chainmsg"
 	"github.com/data-preservation-programs/filsigner-relayed/config"
+	"github.com/data-preservation-programs/filsigner-relayed/cosign"
+	"github.com/data-preservation-programs/filsigner-relayed/datacap"
+	"github.com/data-preservation-programs/filsigner-relayed/dealproposal"
+	"github.com/data-preservation-programs/filsigner-relayed/epochwindow"
+	"github.com/data-preservation-programs/filsigner-relayed/inspect"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/data-preservation-programs/filsigner-relayed/manifest"
 	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/removedatacap"
+	"github.com/data-preservation-programs/filsigner-relayed/replication"
+	"github.com/data-preservation-programs/filsigner-relayed/webhook"
 	"github.com/filecoin-project/go-address"
 	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/filecoin-project/go-state-types/actors"
 	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
-	cbornode "github.com/ipfs/go-ipld-cbor"
+	filnetwork "github.com/filecoin-project/go-state-types/network"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/jpillora/backoff"
-	"github.com/jsign/go-filsigner/wallet"
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
@@ -19,8 +34,10 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/client"
 	"github.com/pkg/errors"
-	"github.com/ybbus/jsonrpc/v3"
 	"io"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -30,35 +47,309 @@ type Server struct {
 	host              host.Host
 	relays            []peer.AddrInfo
 	allowedRequesters []peer.ID
-	keyMap            map[address.Address]WalletPrivateKey
+	keystore          keystore.Keystore
+	keysMu            sync.RWMutex
+	keyMap            map[address.Address]address.Address
+	audit             *audit.Log
+	auditPath         string
+	approvals         *approval.Queue
+	approvalRules     approval.Rules
+	cosignPolicy      *cosign.Policy
+	notifier          *webhook.Notifier
+	providersMu       sync.Mutex
+	knownProviders    map[address.Address]struct{}
+	dealsMu           sync.Mutex
+	signedDeals       map[string]string
+	started           time.Time
+	reservationsMu    sync.Mutex
+	reservations      map[peer.ID]time.Time
+	walletAPITypes    []keystore.MsgType
+	messageTypes      map[address.Address][]string
+	chainRules        map[address.Address][]chainmsg.Rule
+	removalRules      map[address.Address][]removedatacap.Rule
+	removalsMu        sync.Mutex
+	removalIDs        map[string]uint64
+	networkVersion    filnetwork.Version
+	network           config.Network
+	resolver          chain.Resolver
+	epochWindow       epochwindow.Window
+	budgets           []datacap.Budget
+	ledger            *datacap.Ledger
+	replicationLimits replication.Limits
+	replicas          *replication.Tracker
+	manifestPath      string
+	manifestOperators []peer.ID
+	manifestMu        sync.RWMutex
+	manifest          *manifest.Manifest
 }
 
-func resolveShortID(addr address.Address) (address.Address, error) {
+// Option configures optional features of the server
+type Option func(*Server) error
+
+// WithKeystore adds the keys of the keystore to the wallet keys the server signs with
+func WithKeystore(store keystore.Keystore) Option {
+	return func(s *Server) error {
+		s.keystore = keystore.Multi{s.keystore, store}
+		return nil
+	}
+}
+
+// WithAuditLog records every signature, rejection and approval decision in the audit log.
+// Providers and deals that were signed for in previous runs are loaded from the log at auditPath
+// once all the options are applied, so that they are accounted against the configured limits.
+func WithAuditLog(log *audit.Log, auditPath string) Option {
+	return func(s *Server) error {
+		s.audit = log
+		s.auditPath = auditPath
+		return nil
+	}
+}
+
+// replayAudit loads the providers, deals, removals, datacap and replicas signed for in previous runs from the audit log
+func (s *Server) replayAudit() error {
+	if s.auditPath == "" {
+		return nil
+	}
+
+	return audit.Read(s.auditPath, func(record audit.Record) error {
+		if record.Event != audit.Signed && record.Event != audit.Approved {
+			return nil
+		}
+
+		if record.DealUUID != "" && record.ProposalCID != "" {
+			s.signedDeals[record.DealUUID] = record.Client
+		}
+		s.rememberRemoval(record)
+		s.accountDataCap(record)
+
+		provider, err := address.NewFromString(record.Provider)
+		if err != nil {
+			return nil
+		}
+
+		s.knownProviders[provider] = struct{}{}
+		if record.PieceCID != "" {
+			s.replicas.Add(record.PieceCID, provider)
+		}
+		return nil
+	})
+}
+
+// WithApprovalQueue parks the proposals matching the rules in the queue until an operator approves or rejects them
+func WithApprovalQueue(queue *approval.Queue, rules approval.Rules) Option {
+	return func(s *Server) error {
+		s.approvals = queue
+		s.approvalRules = rules
+		return nil
+	}
+}
+
+// WithCosignPolicy requires detached approvals from independent operator keys before signing for the policy wallets
+func WithCosignPolicy(policy *cosign.Policy) Option {
+	return func(s *Server) error {
+		s.cosignPolicy = policy
+		return nil
+	}
+}
+
+// WithWalletAPITypes sets the message types signed through the wallet API. Only deal proposals are signed by default.
+// Chain messages and unknown messages (deal status requests) go through the checks of their sign protocol message type.
+func WithWalletAPITypes(types []keystore.MsgType) Option {
+	return func(s *Server) error {
+		for _, msgType := range types {
+			if _, ok := walletAPIMessageTypes[msgType]; !ok && msgType != keystore.MTDealProposal {
+				return errors.Errorf("message type %s is not signed through the wallet API", msgType)
+			}
+		}
+
+		s.walletAPITypes = types
+		return nil
+	}
+}
+
+// WithNetworkVersion decodes deal proposals with the market actor of the network version,
+// unless the request gives its own network version
+func WithNetworkVersion(nv filnetwork.Version) Option {
+	return func(s *Server) error {
+		_, err := dealproposal.Version(nv)
+		if err != nil {
+			return err
+		}
+
+		s.networkVersion = nv
+		return nil
+	}
+}
+
+// WithNetwork runs the signer on the network, which sets its protocol IDs and the default endpoint ID addresses are resolved with
+func WithNetwork(network config.Network) Option {
+	return func(s *Server) error {
+		s.network = network
+		return nil
+	}
+}
+
+// WithResolver resolves the ID addresses of the wallets with the resolver, instead of the public RPC endpoint of the network
+func WithResolver(resolver chain.Resolver) Option {
+	return func(s *Server) error {
+		s.resolver = resolver
+		return nil
+	}
+}
+
+// WithEpochWindow only signs proposals whose epochs are inside the window at the current epoch of the network
+func WithEpochWindow(window epochwindow.Window) Option {
+	return func(s *Server) error {
+		s.epochWindow = window
+		return nil
+	}
+}
+
+// WithWebhooks sends every audit record as an event to the notifier endpoints
+func WithWebhooks(notifier *webhook.Notifier) Option {
+	return func(s *Server) error {
+		s.notifier = notifier
+		return nil
+	}
+}
+
+func resolveShortID(resolver chain.Resolver, addr address.Address) (address.Address, error) {
 	ctx := context.TODO()
-	client := jsonrpc.NewClient("https://api.node.glif.io/rpc/v0")
-	var shortAddr string
-	err := client.CallFor(ctx, &shortAddr, "Filecoin.StateLookupID", addr.String(), nil)
+	shortAddr, err := resolver.LookupID(ctx, addr)
 	if err != nil {
 		return address.Undef, errors.Wrap(err, "failed to resolve short id")
 	}
 
-	return address.NewFromString(shortAddr)
+	return shortAddr, nil
 }
 
-func NewServer(privateKey crypto.PrivKey, allowedRequesters []peer.ID, walletKeys []WalletPrivateKey, relays []peer.AddrInfo) (*Server, error) {
-	keyMap := make(map[address.Address]WalletPrivateKey)
-	for _, key := range walletKeys {
-		addr, err := wallet.PublicKey(key)
+// loadKeyMap lists the keys in the keystore and resolves their ID addresses with the resolver
+func loadKeyMap(store keystore.Keystore, resolver chain.Resolver) (map[address.Address]address.Address, error) {
+	addrs, err := store.List(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list wallet keys")
+	}
+
+	keyMap := make(map[address.Address]address.Address)
+	for _, addr := range addrs {
+		keyMap[addr] = addr
+		shortAddr, err := resolveShortID(resolver, addr)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to resolve private key to public key (address)")
+			return nil, errors.Wrap(err, "failed to resolve short id")
 		}
+		keyMap[shortAddr] = addr
+	}
+
+	return keyMap, nil
+}
+
+// keys returns the current wallet addresses mapped to their key addresses. The map is replaced on reload, never modified.
+func (s *Server) keys() map[address.Address]address.Address {
+	s.keysMu.RLock()
+	defer s.keysMu.RUnlock()
+	return s.keyMap
+}
+
+// Reload reads the wallet keys, webhook secrets and piece manifest from their files again.
+// Each of them is reloaded on its own, and keeps its previous state if it fails.
+func (s *Server) Reload(ctx context.Context) error {
+	var failures []string
+	err := s.reloadKeys(ctx)
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
+
+	err = s.notifier.Reload()
+	if err != nil {
+		failures = append(failures, errors.Wrap(err, "failed to reload webhook secrets").Error())
+	}
+
+	err = s.reloadManifest()
+	if err != nil {
+		failures = append(failures, err.Error())
+	}
 
-		keyMap[addr] = key
-		shortAddr, err := resolveShortID(addr)
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// reloadKeys reads the wallet keys again and resolves their ID addresses before putting them in use
+func (s *Server) reloadKeys(ctx context.Context) error {
+	store, commit := s.keystore, func() {}
+	if reloader, ok := s.keystore.(keystore.Reloader); ok {
+		var err error
+		store, commit, err = reloader.Prepare(ctx)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to resolve short id")
+			return errors.Wrap(err, "failed to reload keystore")
 		}
-		keyMap[shortAddr] = key
+	}
+
+	keyMap, err := loadKeyMap(store, s.resolver)
+	if err != nil {
+		return err
+	}
+
+	commit()
+	s.keysMu.Lock()
+	s.keyMap = keyMap
+	s.keysMu.Unlock()
+	logging.Logger("server").Infow("reloaded", "addresses", len(keyMap))
+	return nil
+}
+
+func NewServer(privateKey crypto.PrivKey, allowedRequesters []peer.ID, walletKeys []WalletPrivateKey, relays []peer.AddrInfo, options ...Option) (*Server, error) {
+	static, err := keystore.NewStatic(walletKeys)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve private key to public key (address)")
+	}
+
+	server := &Server{
+		relays:            relays,
+		allowedRequesters: allowedRequesters,
+		keystore:          static,
+		knownProviders:    make(map[address.Address]struct{}),
+		signedDeals:       make(map[string]string),
+		removalIDs:        make(map[string]uint64),
+		reservations:      make(map[peer.ID]time.Time),
+		network:           config.Mainnet,
+		ledger:            datacap.NewLedger(),
+		replicas:          replication.NewTracker(),
+	}
+
+	for _, option := range options {
+		err := option(server)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to apply server option")
+		}
+	}
+
+	err = server.replayAudit()
+	if err != nil {
+		return nil, err
+	}
+
+	if server.resolver == nil {
+		server.resolver = chain.NewRPCResolver(server.network.RPCEndpoint, "")
+	}
+
+	server.keyMap, err = loadKeyMap(server.keystore, server.resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	if server.epochWindow.Enabled() && server.network.Genesis.IsZero() {
+		return nil, errors.New("epoch windows require the genesis time of the network")
+	}
+
+	if server.approvalRules.Enabled() && server.approvals == nil {
+		return nil, errors.New("approval rules require an approval queue")
+	}
+
+	if server.manifest != nil {
+		server.recordManifest(server.manifest)
 	}
 
 	host, err := libp2p.New(
@@ -70,12 +361,8 @@ func NewServer(privateKey crypto.PrivKey, allowedRequesters []peer.ID, walletKey
 		return nil, errors.Wrap(err, "failed to create libp2p host")
 	}
 
-	return &Server{
-		host:              host,
-		relays:            relays,
-		allowedRequesters: allowedRequesters,
-		keyMap:            keyMap,
-	}, nil
+	server.host = host
+	return server, nil
 }
 
 func SendError(stream network.Stream, code model.StatusCode, message string) {
@@ -90,94 +377,704 @@ func SendError(stream network.Stream, code model.StatusCode, message string) {
 	}
 }
 
-func (s Server) Start(ctx context.Context) error {
-	log := logging.Logger("server")
-	// Setup stream handler
-	s.host.SetStreamHandler(config.ProtocolName, func(stream network.Stream) {
-		log := log.With("remote", stream.Conn().RemotePeer().String())
-		log.Info("got sign proposal request")
-		defer stream.Close()
-
-		// Verify that the request is from allowed requesters
-		allowed := false
-		for _, allowedRequester := range s.allowedRequesters {
-			if stream.Conn().RemotePeer() == allowedRequester {
-				allowed = true
-				break
-			}
+func (s *Server) isAllowed(requester peer.ID) bool {
+	for _, allowedRequester := range s.allowedRequesters {
+		if requester == allowedRequester {
+			return true
 		}
-		if !allowed {
-			SendError(stream, model.UnauthorizedRequester, "request is not from allowed requesters")
-			return
+	}
+
+	return false
+}
+
+func (s *Server) isKnownProvider(provider address.Address) bool {
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+	_, ok := s.knownProviders[provider]
+	return ok
+}
+
+func proposalRecord(event audit.Event, requester string, proposal *filmarket.DealProposal) audit.Record {
+	record := audit.Record{
+		Event:     event,
+		Requester: requester,
+	}
+	if proposal == nil {
+		return record
+	}
+
+	record.Client = proposal.Client.String()
+	record.Provider = proposal.Provider.String()
+	record.PieceCID = proposal.PieceCID.String()
+	record.PieceSize = uint64(proposal.PieceSize)
+	record.VerifiedDeal = proposal.VerifiedDeal
+	proposalCid, err := proposal.Cid()
+	if err == nil {
+		record.ProposalCID = proposalCid.String()
+	}
+
+	return record
+}
+
+func (s *Server) record(record audit.Record) {
+	if record.Event == audit.Signed || record.Event == audit.Approved {
+		provider, err := address.NewFromString(record.Provider)
+		if err == nil {
+			s.providersMu.Lock()
+			s.knownProviders[provider] = struct{}{}
+			s.providersMu.Unlock()
 		}
 
-		// Read the proposal bytes
-		request, err := io.ReadAll(stream)
-		if err != nil {
-			SendError(stream, model.ReadStreamError, err.Error())
-			return
+		if record.DealUUID != "" && record.ProposalCID != "" {
+			s.dealsMu.Lock()
+			s.signedDeals[record.DealUUID] = record.Client
+			s.dealsMu.Unlock()
+		}
+		s.rememberRemoval(record)
+		if record.VerifiedDeal {
+			signedDataCap.WithLabelValues(s.walletLabel(record.Client), record.Requester, record.Provider).Add(float64(record.PieceSize))
 		}
+	}
 
-		// Unmarshall to the proposal object
-		proposal := new(filmarket.DealProposal)
-		err = cbornode.DecodeInto(request, proposal)
-		if err != nil {
-			SendError(stream, model.DecodeRequestError, err.Error())
-			return
+	err := s.audit.Append(record)
+	if err != nil {
+		logging.Logger("server").Errorw("failed to write audit record", "error", err)
+	}
+
+	err = s.notifier.Notify(record)
+	if err != nil {
+		logging.Logger("server").Errorw("failed to queue webhook event", "error", err)
+	}
+}
+
+func (s *Server) reject(requester peer.ID, proposal *filmarket.DealProposal, code model.StatusCode, message string) *model.SignerResponse {
+	return s.rejectRecord(proposalRecord(audit.Rejected, requester.String(), proposal), code, message)
+}
+
+// rejectRecord records the rejection of the request described by the record
+func (s *Server) rejectRecord(record audit.Record, code model.StatusCode, message string) *model.SignerResponse {
+	logging.Logger("server").Errorw("rejecting request", "remote", record.Requester, "code", code, "message", message)
+	record.Code = model.StatusCodeString[code]
+	record.Message = message
+	s.record(record)
+	return &model.SignerResponse{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// rejectMismatch rejects a proposal that does not round-trip, telling the requester where the encodings diverge
+func (s *Server) rejectMismatch(requester peer.ID, proposal *filmarket.DealProposal, request []byte) *model.SignerResponse {
+	message := "proposal remarshalled does not match the original proposal bytes"
+	diffs, err := inspect.DiffProposal(request)
+	if err != nil || len(diffs) == 0 {
+		return s.reject(requester, proposal, model.ProposalRemarshalMismatch, message)
+	}
+
+	first := diffs[0]
+	response := s.reject(requester, proposal, model.ProposalRemarshalMismatch,
+		fmt.Sprintf("%s: field %s differs at byte %d", message, first.Field, first.Offset))
+	response.Mismatch = &model.RemarshalMismatch{
+		Offset:       uint64(first.Offset),
+		Field:        first.Field,
+		Original:     first.Original,
+		Remarshalled: first.Remarshalled,
+	}
+	return response
+}
+
+// checkEpochs checks the proposal against the epoch window at the current epoch
+func (s *Server) checkEpochs(proposal *filmarket.DealProposal) (model.StatusCode, error) {
+	if !s.epochWindow.Enabled() {
+		return model.Success, nil
+	}
+
+	current, err := s.network.Epoch(time.Now())
+	if err != nil {
+		return model.EpochOutOfWindow, err
+	}
+
+	err = s.epochWindow.Check(proposal, current)
+	if err != nil {
+		return model.EpochOutOfWindow, err
+	}
+
+	return model.Success, nil
+}
+
+// cosignRequired reports whether the client is one of the two-person rule wallets, by any of its addresses
+func (s *Server) cosignRequired(client address.Address) bool {
+	if !s.cosignPolicy.Enabled() {
+		return false
+	}
+
+	keyMap := s.keys()
+	for _, wallet := range s.cosignPolicy.Wallets {
+		if wallet == client {
+			return true
 		}
 
-		log.Infow("proposal decoded", "proposal", proposal)
+		key, ok := keyMap[wallet]
+		if ok && key == keyMap[client] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) sign(proposal *filmarket.DealProposal, proposalBytes []byte) ([]byte, model.StatusCode, error) {
+	keyAddr, ok := s.keys()[proposal.Client]
+	if !ok {
+		return nil, model.WalletKeyNotFound, errors.New("private key not found for the proposal client address " + proposal.Client.String())
+	}
 
-		// Verify the original proposal is properly marshalled
-		proposalBytes, err := cborutil.Dump(proposal)
+	if s.cosignRequired(proposal.Client) {
+		err := s.cosignPolicy.Check(proposal, time.Now())
 		if err != nil {
-			SendError(stream, model.EncodeRequestError, err.Error())
-			return
+			return nil, model.CosignatureRequired, err
 		}
+	}
+
+	signature, err := s.keystore.Sign(context.Background(), keyAddr, proposalBytes, keystore.MsgMeta{Type: keystore.MTDealProposal})
+	if err != nil {
+		return nil, model.WalletSignError, err
+	}
+
+	signatureBytes, err := signature.MarshalBinary()
+	if err != nil {
+		return nil, model.MarshalSignatureError, err
+	}
+
+	return signatureBytes, model.Success, nil
+}
+
+// decodeProposal decodes the proposal with the market actor of the network version, or the configured one if it is 0,
+// the way signDealProposal does. It returns nil if the proposal cannot be decoded.
+func (s *Server) decodeProposal(data []byte, nv filnetwork.Version) *filmarket.DealProposal {
+	if nv == 0 {
+		nv = s.networkVersion
+	}
+	version, err := dealproposal.Version(nv)
+	if err != nil {
+		return nil
+	}
+
+	proposal, err := dealproposal.Decode(data, version)
+	if err != nil {
+		return nil
+	}
 
-		if !bytes.Equal(request, proposalBytes) {
-			SendError(stream, model.ProposalRemarshalMismatch, "proposal remarshalled does not match the original proposal bytes")
-			return
+	return proposal
+}
+
+// signProposal runs the full signing pipeline for the proposal bytes sent by the requester
+func (s *Server) signProposal(requester peer.ID, request []byte) *model.SignerResponse {
+	return s.signDealProposal(requester, request, "", 0)
+}
+
+// signDealProposal signs the proposal of the Boost deal with the UUID, which is empty if the requester did not give it.
+// The proposal is decoded for the network version of the request, or the configured one if it is 0.
+func (s *Server) signDealProposal(requester peer.ID, request []byte, dealUUID string, nv filnetwork.Version) *model.SignerResponse {
+	log := logging.Logger("server").With("remote", requester.String())
+
+	if nv == 0 {
+		nv = s.networkVersion
+	}
+	version, err := dealproposal.Version(nv)
+	if err != nil {
+		return s.reject(requester, nil, model.DecodeRequestError, err.Error())
+	}
+
+	// Unmarshall to the proposal object
+	proposal, err := dealproposal.Decode(request, version)
+	if err != nil {
+		return s.reject(requester, nil, model.DecodeRequestError, err.Error())
+	}
+
+	log.Infow("proposal decoded", "proposal", proposal, "actorsVersion", version)
+
+	// Verify the original proposal is properly marshalled
+	proposalBytes, err := dealproposal.Marshal(proposal, version)
+	if err != nil {
+		return s.reject(requester, proposal, model.EncodeRequestError, err.Error())
+	}
+
+	if !bytes.Equal(request, proposalBytes) {
+		return s.rejectMismatch(requester, proposal, request)
+	}
+
+	if _, ok := s.keys()[proposal.Client]; !ok {
+		return s.reject(requester, proposal, model.WalletKeyNotFound, "private key not found for the proposal client address "+proposal.Client.String())
+	}
+
+	if !s.messageTypeAllowed(proposal.Client, model.MessageTypeDealProposal) {
+		return s.reject(requester, proposal, model.MessageTypeNotAllowed, "wallet "+proposal.Client.String()+" does not sign deal proposals")
+	}
+
+	code, err := s.checkEpochs(proposal)
+	if err != nil {
+		return s.reject(requester, proposal, code, err.Error())
+	}
+
+	manifestVersion, code, err := s.checkManifest(proposal)
+	if err != nil {
+		return s.reject(requester, proposal, code, err.Error())
+	}
+
+	remaining, releaseDataCap, code, err := s.reserveDataCap(requester.String(), proposal)
+	if err != nil {
+		response := s.reject(requester, proposal, code, err.Error())
+		response.RemainingDataCap = remaining
+		return response
+	}
+
+	releaseReplica, code, err := s.reserveReplica(proposal)
+	if err != nil {
+		releaseDataCap()
+		return s.reject(requester, proposal, code, err.Error())
+	}
+
+	release := func() {
+		releaseDataCap()
+		releaseReplica()
+	}
+
+	// Park the proposal for manual approval if required. The datacap and replica are reserved again when it is approved.
+	if s.approvalRules.Enabled() {
+		response, parked := s.park(requester, proposal, proposalBytes, dealUUID, version)
+		if parked {
+			release()
+			return response
 		}
+	}
+
+	// Sign the proposal
+	signatureBytes, code, err := s.sign(proposal, proposalBytes)
+	if err != nil {
+		release()
+		return s.reject(requester, proposal, code, err.Error())
+	}
 
-		// Sign the proposal
-		privateKey, ok := s.keyMap[proposal.Client]
-		if !ok {
-			SendError(stream, model.WalletKeyNotFound, "private key not found for the proposal client address "+proposal.Client.String())
-			return
+	record := proposalRecord(audit.Signed, requester.String(), proposal)
+	record.DealUUID = dealUUID
+	record.ActorsVersion = int(version)
+	record.ManifestVersion = manifestVersion
+	s.record(record)
+	return &model.SignerResponse{
+		Code:             model.Success,
+		Signature:        signatureBytes,
+		RemainingDataCap: remaining,
+	}
+}
+
+// park checks whether the proposal needs manual approval, and if so returns the state of its ticket
+func (s *Server) park(requester peer.ID, proposal *filmarket.DealProposal, proposalBytes []byte, dealUUID string, version actors.Version) (*model.SignerResponse, bool) {
+	proposalCid, err := proposal.Cid()
+	if err != nil {
+		return s.reject(requester, proposal, model.EncodeRequestError, err.Error()), true
+	}
+
+	ticket, err := s.approvals.Get(proposalCid.String())
+	if err == nil {
+		return ticketResponse(ticket), true
+	}
+	if !errors.Is(err, approval.ErrTicketNotFound) {
+		return s.reject(requester, proposal, model.ApprovalQueueError, err.Error()), true
+	}
+
+	reasons := s.approvalRules.Check(proposal, s.isKnownProvider)
+	if len(reasons) == 0 {
+		return nil, false
+	}
+
+	ticket = &approval.Ticket{
+		ID:            proposalCid.String(),
+		Status:        approval.Pending,
+		Requester:     requester.String(),
+		Reasons:       reasons,
+		Client:        proposal.Client.String(),
+		Provider:      proposal.Provider.String(),
+		PieceCID:      proposal.PieceCID.String(),
+		PieceSize:     uint64(proposal.PieceSize),
+		Proposal:      proposalBytes,
+		DealUUID:      dealUUID,
+		Created:       time.Now().UTC(),
+		ActorsVersion: int(version),
+	}
+	err = s.approvals.Put(ticket)
+	if err != nil {
+		return s.reject(requester, proposal, model.ApprovalQueueError, err.Error()), true
+	}
+
+	record := proposalRecord(audit.PendingApproval, requester.String(), proposal)
+	record.Ticket = ticket.ID
+	record.DealUUID = dealUUID
+	record.Message = ticket.Reasons[0]
+	record.ActorsVersion = int(version)
+	s.record(record)
+	return ticketResponse(ticket), true
+}
+
+// ticketActorsVersion returns the market actor version the parked proposal was decoded with.
+// Tickets parked before the version was stored use the latest version.
+func ticketActorsVersion(ticket *approval.Ticket) actors.Version {
+	if ticket.ActorsVersion == 0 {
+		return dealproposal.Latest
+	}
+
+	return actors.Version(ticket.ActorsVersion)
+}
+
+// ticketProposal decodes the parked proposal with the market actor version of the ticket
+func ticketProposal(ticket *approval.Ticket) (*filmarket.DealProposal, error) {
+	proposal, err := dealproposal.Decode(ticket.Proposal, ticketActorsVersion(ticket))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode parked proposal")
+	}
+
+	return proposal, nil
+}
+
+func ticketResponse(ticket *approval.Ticket) *model.SignerResponse {
+	switch ticket.Status {
+	case approval.Approved:
+		return &model.SignerResponse{
+			Code:      model.Success,
+			Signature: ticket.Signature,
+			Ticket:    ticket.ID,
 		}
+	case approval.Rejected:
+		return &model.SignerResponse{
+			Code:    model.ApprovalRejected,
+			Message: "proposal was rejected by the operator: " + ticket.Note,
+			Ticket:  ticket.ID,
+		}
+	default:
+		return &model.SignerResponse{
+			Code:    model.PendingApproval,
+			Message: "proposal is pending manual approval",
+			Ticket:  ticket.ID,
+		}
+	}
+}
+
+func (s *Server) ListTickets() ([]*approval.Ticket, error) {
+	if s.approvals == nil {
+		return nil, errors.New("approval queue is not enabled")
+	}
 
-		signature, err := wallet.WalletSign(privateKey, proposalBytes)
+	return s.approvals.List()
+}
+
+func (s *Server) ApproveTicket(id string, note string) (*approval.Ticket, error) {
+	if s.approvals == nil {
+		return nil, errors.New("approval queue is not enabled")
+	}
+
+	var proposal *filmarket.DealProposal
+	var manifestVersion string
+	ticket, err := s.approvals.Decide(id, approval.Approved, note, func(ticket *approval.Ticket) ([]byte, error) {
+		var err error
+		proposal, err = ticketProposal(ticket)
 		if err != nil {
-			SendError(stream, model.WalletSignError, err.Error())
-			return
+			return nil, err
 		}
 
-		// Marshall the signature
-		signatureBytes, err := signature.MarshalBinary()
+		_, err = s.checkEpochs(proposal)
 		if err != nil {
-			SendError(stream, model.MarshalSignatureError, err.Error())
+			return nil, err
 		}
 
-		response := &model.SignerResponse{
-			Code:      model.Success,
-			Signature: signatureBytes,
+		manifestVersion, _, err = s.checkManifest(proposal)
+		if err != nil {
+			return nil, err
+		}
+
+		_, releaseDataCap, _, err := s.reserveDataCap(ticket.Requester, proposal)
+		if err != nil {
+			return nil, err
 		}
 
-		// Marshall the response
-		responseBytes, err := cborutil.Dump(response)
+		releaseReplica, _, err := s.reserveReplica(proposal)
 		if err != nil {
-			SendError(stream, model.EncodeResponseError, err.Error())
-			return
+			releaseDataCap()
+			return nil, err
 		}
 
-		// Send back the signature
-		_, err = stream.Write(responseBytes)
+		signature, _, err := s.sign(proposal, ticket.Proposal)
 		if err != nil {
-			log.Errorw("failed to sent the response back", "error", err)
-			return
+			releaseDataCap()
+			releaseReplica()
+		}
+		return signature, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record := proposalRecord(audit.Approved, ticket.Requester, proposal)
+	record.Ticket = ticket.ID
+	record.DealUUID = ticket.DealUUID
+	record.Message = note
+	record.ActorsVersion = int(ticketActorsVersion(ticket))
+	record.ManifestVersion = manifestVersion
+	s.record(record)
+	return ticket, nil
+}
+
+func (s *Server) SubmitApproval(signed cosign.SignedApproval) error {
+	if !s.cosignPolicy.Enabled() {
+		return errors.New("two-person rule is not enabled")
+	}
+
+	return s.cosignPolicy.Submit(signed)
+}
+
+func (s *Server) RejectTicket(id string, note string) (*approval.Ticket, error) {
+	if s.approvals == nil {
+		return nil, errors.New("approval queue is not enabled")
+	}
+
+	ticket, err := s.approvals.Decide(id, approval.Rejected, note, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal, _ := ticketProposal(ticket)
+	record := proposalRecord(audit.ApprovalRejected, ticket.Requester, proposal)
+	record.Ticket = ticket.ID
+	record.Message = note
+	s.record(record)
+	return ticket, nil
+}
+
+func writeResponse(stream network.Stream, response *model.SignerResponse) {
+	// Marshall the response
+	responseBytes, err := cborutil.Dump(response)
+	if err != nil {
+		SendError(stream, model.EncodeResponseError, err.Error())
+		return
+	}
+
+	// Send back the response
+	_, err = stream.Write(responseBytes)
+	if err != nil {
+		logging.Logger("server").Errorw("failed to sent the response back", "error", err)
+	}
+}
+
+func (s *Server) handleSignProposal(stream network.Stream) {
+	log := logging.Logger("server").With("remote", stream.Conn().RemotePeer().String())
+	log.Info("got sign proposal request")
+	defer stream.Close()
+
+	// Verify that the request is from allowed requesters
+	requester := stream.Conn().RemotePeer()
+	if !s.isAllowed(requester) {
+		SendError(stream, model.UnauthorizedRequester, "request is not from allowed requesters")
+		return
+	}
+
+	// Read the proposal bytes
+	request, err := io.ReadAll(stream)
+	if err != nil {
+		writeResponse(stream, s.reject(requester, nil, model.ReadStreamError, err.Error()))
+		return
+	}
+
+	writeResponse(stream, s.signProposal(requester, request))
+}
+
+func (s *Server) handleTicket(stream network.Stream) {
+	log := logging.Logger("server").With("remote", stream.Conn().RemotePeer().String())
+	log.Info("got ticket request")
+	defer stream.Close()
+
+	if !s.isAllowed(stream.Conn().RemotePeer()) {
+		SendError(stream, model.UnauthorizedRequester, "request is not from allowed requesters")
+		return
+	}
+
+	request, err := io.ReadAll(stream)
+	if err != nil {
+		SendError(stream, model.ReadStreamError, err.Error())
+		return
+	}
+
+	if s.approvals == nil {
+		SendError(stream, model.TicketNotFound, "approval queue is not enabled")
+		return
+	}
+
+	ticket, err := s.approvals.Get(string(request))
+	if err != nil {
+		SendError(stream, model.TicketNotFound, err.Error())
+		return
+	}
+
+	writeResponse(stream, ticketResponse(ticket))
+}
+
+// info builds the capability document advertised to allowed requesters
+func (s *Server) info() *model.SignerInfo {
+	info := &model.SignerInfo{
+		Code: model.Success,
+		Protocols: []model.ProtocolInfo{
+			{ID: string(s.network.Protocol(config.ProtocolName))},
+			{ID: string(s.network.Protocol(config.SignProtocolName))},
+			{ID: string(s.network.Protocol(config.TicketProtocolName))},
+			{ID: string(s.network.Protocol(config.InfoProtocolName))},
+			{ID: string(s.network.Protocol(config.PingProtocolName))},
+		},
+		Policy: model.PolicyInfo{
+			ApprovalPieceSizeAbove: uint64(s.approvalRules.PieceSizeAbove),
+			ApprovalNewProviders:   s.approvalRules.NewProviders,
+			ChainMessageRules:      ruleInfos(s.chainRules),
+			RemoveDataCapRules:     ruleInfos(s.removalRules),
+			StartMinLead:           int64(s.epochWindow.MinLead),
+			StartMaxLead:           int64(s.epochWindow.MaxLead),
+			MinDuration:            int64(s.epochWindow.MinDuration),
+			MaxDuration:            int64(s.epochWindow.MaxDuration),
+			EndMaxLead:             int64(s.epochWindow.MaxEndLead),
+			MaxReplicas:            uint64(s.replicationLimits.MaxReplicas),
+			MaxReplicasPerProvider: uint64(s.replicationLimits.MaxPerProvider),
+			MaxReplicasPerGroup:    uint64(s.replicationLimits.MaxPerGroup),
+		},
+	}
+	if s.approvalRules.PricePerEpochAbove != nil {
+		info.Policy.ApprovalPriceAbove = s.approvalRules.PricePerEpochAbove.String()
+	}
+
+	for _, budget := range s.budgets {
+		budgetInfo := model.BudgetInfo{
+			Scope:  string(budget.Scope),
+			Window: uint64(budget.Window / time.Second),
+			Limit:  budget.Limit,
+		}
+		if budget.Wallet != address.Undef {
+			budgetInfo.Wallet = budget.Wallet.String()
 		}
+		info.Policy.DataCapBudgets = append(info.Policy.DataCapBudgets, budgetInfo)
+	}
+
+	if current := s.pieceManifest(); current != nil {
+		info.Policy.ManifestVersion = current.Version
+	}
+
+	for _, msgType := range model.MessageTypes {
+		info.MessageTypes = append(info.MessageTypes, model.MessageTypeInfo{Name: msgType})
+	}
+
+	keyMap := s.keys()
+	for addr, key := range keyMap {
+		if addr.Protocol() == address.ID {
+			continue
+		}
+
+		wallet := model.WalletInfo{
+			Address: addr.String(),
+			Cosign:  s.cosignRequired(addr),
+		}
+		for _, msgType := range s.allowedMessageTypes(addr) {
+			wallet.MessageTypes = append(wallet.MessageTypes, model.MessageTypeInfo{Name: msgType})
+		}
+		for alias, aliasKey := range keyMap {
+			if alias.Protocol() == address.ID && aliasKey == key {
+				wallet.IDAddress = alias.String()
+			}
+		}
+
+		info.Wallets = append(info.Wallets, wallet)
+	}
+
+	sort.Slice(info.Wallets, func(i, j int) bool {
+		return info.Wallets[i].Address < info.Wallets[j].Address
 	})
+	return info
+}
+
+// ruleInfos lists the typed message rules of each wallet, ordered by wallet
+func ruleInfos[T fmt.Stringer](rules map[address.Address][]T) []model.RuleInfo {
+	var infos []model.RuleInfo
+	for wallet, walletRules := range rules {
+		for _, rule := range walletRules {
+			infos = append(infos, model.RuleInfo{Wallet: wallet.String(), Rule: rule.String()})
+		}
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Wallet < infos[j].Wallet
+	})
+	return infos
+}
+
+func (s *Server) handleInfo(stream network.Stream) {
+	log := logging.Logger("server").With("remote", stream.Conn().RemotePeer().String())
+	log.Info("got info request")
+	defer stream.Close()
+
+	info := s.info()
+	if !s.isAllowed(stream.Conn().RemotePeer()) {
+		info = &model.SignerInfo{
+			Code:    model.UnauthorizedRequester,
+			Message: "request is not from allowed requesters",
+		}
+	}
+
+	err := cborutil.WriteCborRPC(stream, info)
+	if err != nil {
+		log.Errorw("failed to sent the info back", "error", err)
+	}
+}
+
+// activeReservations counts the relays that are connected and hold an unexpired reservation for this server
+func (s *Server) activeReservations() uint64 {
+	s.reservationsMu.Lock()
+	defer s.reservationsMu.Unlock()
+	var count uint64
+	for relay, expiration := range s.reservations {
+		if time.Now().Before(expiration) && s.host.Network().Connectedness(relay) == network.Connected {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (s *Server) handlePing(stream network.Stream) {
+	log := logging.Logger("server").With("remote", stream.Conn().RemotePeer().String())
+	log.Debug("got ping request")
+	defer stream.Close()
+
+	response := &model.PingResponse{
+		Code:             model.Success,
+		UptimeSeconds:    uint64(time.Since(s.started).Seconds()),
+		Reservations:     s.activeReservations(),
+		KeystoreUnlocked: len(s.keys()) > 0,
+	}
+	if !s.isAllowed(stream.Conn().RemotePeer()) {
+		response = &model.PingResponse{
+			Code:    model.UnauthorizedRequester,
+			Message: "request is not from allowed requesters",
+		}
+	}
+
+	err := cborutil.WriteCborRPC(stream, response)
+	if err != nil {
+		log.Errorw("failed to sent the ping response back", "error", err)
+	}
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	log := logging.Logger("server")
+	s.started = time.Now()
+	// Setup stream handlers
+	s.host.SetStreamHandler(s.network.Protocol(config.ProtocolName), s.handleSignProposal)
+	s.host.SetStreamHandler(s.network.Protocol(config.SignProtocolName), s.handleSign)
+	s.host.SetStreamHandler(s.network.Protocol(config.TicketProtocolName), s.handleTicket)
+	s.host.SetStreamHandler(s.network.Protocol(config.InfoProtocolName), s.handleInfo)
+	s.host.SetStreamHandler(s.network.Protocol(config.PingProtocolName), s.handlePing)
 
 	// Start connection to relay servers
 	for _, relay := range s.relays {
@@ -217,6 +1114,9 @@ func (s Server) Start(ctx context.Context) error {
 					}
 
 					log.Infow("reserved spot", "reservation", reservation)
+					s.reservationsMu.Lock()
+					s.reservations[relay.ID] = reservation.Expiration
+					s.reservationsMu.Unlock()
 					waitTime.Reset()
 				}
 				select {
@@ -1,8 +1,33 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/chain"
+	"github.com/data-preservation-programs/filsigner-relayed/config"
+	"github.com/data-preservation-programs/filsigner-relayed/datacap"
+	"github.com/data-preservation-programs/filsigner-relayed/epochwindow"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/data-preservation-programs/filsigner-relayed/manifest"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/replication"
 	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/filecoin-project/go-state-types/abi"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	cbornode "github.com/ipfs/go-ipld-cbor"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestResolveShortID(t *testing.T) {
@@ -12,7 +37,7 @@ func TestResolveShortID(t *testing.T) {
 		t.Fatalf("err is not null: %v", err)
 	}
 
-	shortAddr, err := resolveShortID(addr)
+	shortAddr, err := resolveShortID(chain.NewRPCResolver(config.Mainnet.RPCEndpoint, ""), addr)
 	if err != nil {
 		t.Fatalf("err is not null: %v", err)
 	}
@@ -21,3 +46,321 @@ func TestResolveShortID(t *testing.T) {
 		t.Fatalf("short addresss is incorrect: %v", shortAddr.String())
 	}
 }
+
+func TestEpochWindowGenesis(t *testing.T) {
+	identityKey, _ := newTestOperator(t)
+	_, err := NewServer(identityKey, nil, []string{testWalletKey}, nil,
+		WithResolver(&testResolver{}), WithNetwork(config.Devnet), WithEpochWindow(epochwindow.Window{MaxLead: 100}))
+	if err == nil {
+		t.Fatalf("expected epoch window without genesis time to be refused")
+	}
+
+	// Devnets have no genesis time, which is fine as long as no window is enforced
+	server, _ := newTestServer(t, WithNetwork(config.Devnet), WithEpochWindow(epochwindow.Window{}))
+	if server.epochWindow.Enabled() {
+		t.Fatalf("expected no epoch window")
+	}
+}
+
+func TestEpochWindow(t *testing.T) {
+	server, clientAddr := newTestServer(t, WithNetwork(config.Mainnet), WithEpochWindow(epochwindow.Window{MaxLead: 100, MinDuration: 1000}))
+	current, err := config.Mainnet.Epoch(time.Now())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	proposalBytes := func(start abi.ChainEpoch, end abi.ChainEpoch) []byte {
+		proposal := new(filmarket.DealProposal)
+		err := cbornode.DecodeInto(testProposal(t, clientAddr), proposal)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		proposal.StartEpoch = start
+		proposal.EndEpoch = end
+		data, err := cborutil.Dump(proposal)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		return data
+	}
+
+	requester := peer.ID("requester")
+	response := server.signProposal(requester, proposalBytes(current+50, current+2000))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	for _, epochs := range [][2]abi.ChainEpoch{{0, 0}, {current - 10, current + 2000}, {current + 200, current + 2000}, {current + 50, current + 500}} {
+		response = server.signProposal(requester, proposalBytes(epochs[0], epochs[1]))
+		if response.Code != model.EpochOutOfWindow {
+			t.Fatalf("unexpected response for %v: %v", epochs, response)
+		}
+	}
+}
+
+func TestDataCapBudget(t *testing.T) {
+	budgets := []datacap.Budget{{Scope: datacap.ScopeRequester, Window: time.Hour, Limit: 512}}
+	server, clientAddr := newTestServer(t, WithDataCapBudgets(budgets))
+	for i, expected := range []uint64{256, 0} {
+		response := server.signProposal("requester-a", testProposal(t, clientAddr))
+		if response.Code != model.Success || response.RemainingDataCap == nil || *response.RemainingDataCap != expected {
+			t.Fatalf("unexpected response %d: %v", i, response)
+		}
+	}
+
+	response := server.signProposal("requester-a", testProposal(t, clientAddr))
+	if response.Code != model.DataCapBudgetExceeded || *response.RemainingDataCap != 0 {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	response = server.signProposal("requester-b", testProposal(t, clientAddr))
+	if response.Code != model.Success || *response.RemainingDataCap != 256 {
+		t.Fatalf("unexpected response: %v", response)
+	}
+}
+
+func TestDataCapReplay(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(auditPath)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	defer auditLog.Close()
+
+	// Proposals for the ID address of the wallet are labelled with its key address
+	server, clientAddr := newTestServer(t, WithAuditLog(auditLog, auditPath))
+	idAddr, err := address.NewIDAddress(1000)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	proposal := new(filmarket.DealProposal)
+	err = cbornode.DecodeInto(testProposal(t, clientAddr), proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	proposal.Client = idAddr
+	request, err := cborutil.Dump(proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	signed := signedDataCap.WithLabelValues(clientAddr.String(), peer.ID("requester").String(), proposal.Provider.String())
+	before := testutil.ToFloat64(signed)
+	response := server.signProposal("requester", request)
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+	if testutil.ToFloat64(signed) != before+256 {
+		t.Fatalf("expected the signed datacap to be labelled with the key address")
+	}
+
+	// Nothing is kept in the ledger without budgets
+	budget := datacap.Budget{Scope: datacap.ScopeWallet, Window: time.Hour, Limit: 512}
+	wallets := map[string]bool{idAddr.String(): true}
+	entry := datacap.Entry{Wallet: idAddr.String()}
+	if server.ledger.Used(budget, wallets, entry, time.Now()) != 0 {
+		t.Fatalf("expected no datacap in the ledger without budgets")
+	}
+
+	// The budgets apply to the datacap signed before the restart whatever the order of the options
+	restarted, _ := newTestServer(t, WithAuditLog(auditLog, auditPath), WithDataCapBudgets([]datacap.Budget{budget}))
+	if restarted.ledger.Used(budget, wallets, entry, time.Now()) != 256 {
+		t.Fatalf("expected the signed datacap to be replayed")
+	}
+}
+
+func TestDataCapBudgetConcurrent(t *testing.T) {
+	budgets := []datacap.Budget{{Scope: datacap.ScopeWallet, Window: time.Hour, Limit: 256}}
+	server, clientAddr := newTestServer(t, WithDataCapBudgets(budgets))
+	request := testProposal(t, clientAddr)
+	var wg sync.WaitGroup
+	codes := make([]model.StatusCode, 8)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = server.signProposal(peer.ID(fmt.Sprintf("requester-%d", i)), request).Code
+		}(i)
+	}
+	wg.Wait()
+
+	signed := 0
+	for _, code := range codes {
+		if code == model.Success {
+			signed++
+		} else if code != model.DataCapBudgetExceeded {
+			t.Fatalf("unexpected code: %v", code)
+		}
+	}
+
+	if signed != 1 {
+		t.Fatalf("expected exactly one proposal within the budget, got %d", signed)
+	}
+}
+
+func TestReplicationLimits(t *testing.T) {
+	server, clientAddr := newTestServer(t, WithReplicationLimits(replication.Limits{MaxReplicas: 2, MaxPerProvider: 1}))
+	requester := peer.ID("requester")
+	response := server.signProposal(requester, testProposal(t, clientAddr))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	response = server.signProposal(requester, testProposal(t, clientAddr))
+	if response.Code != model.ProviderReplicaLimitExceeded {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	proposal := new(filmarket.DealProposal)
+	err := cbornode.DecodeInto(testProposal(t, clientAddr), proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	for _, expected := range []model.StatusCode{model.Success, model.ReplicaLimitExceeded} {
+		proposal.Provider, err = address.NewIDAddress(uint64(2000 + expected))
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		proposalBytes, err := cborutil.Dump(proposal)
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		response = server.signProposal(requester, proposalBytes)
+		if response.Code != expected {
+			t.Fatalf("unexpected response: %v", response)
+		}
+	}
+}
+
+// newTestOperator generates a piece manifest operator key
+func newTestOperator(t *testing.T) (crypto.PrivKey, peer.ID) {
+	t.Helper()
+	key, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	operator, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	return key, operator
+}
+
+func writeTestManifest(t *testing.T, key crypto.PrivKey, path string, content string) {
+	t.Helper()
+	signed, err := manifest.Sign(key, []byte(content))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	signedBytes, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = os.WriteFile(path, signedBytes, 0o600)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+}
+
+func TestPieceManifest(t *testing.T) {
+	key, operator := newTestOperator(t)
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	writeTestManifest(t, key, path, "baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa,512\n")
+	server, clientAddr := newTestServer(t, WithPieceManifest(path, []peer.ID{operator}))
+	requester := peer.ID("requester")
+	response := server.signProposal(requester, testProposal(t, clientAddr))
+	if response.Code != model.PieceNotInManifest {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	writeTestManifest(t, key, path, "baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa,256\n")
+	err := server.reloadManifest()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	response = server.signProposal(requester, testProposal(t, clientAddr))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	// A manifest that cannot be verified keeps the previous one in place
+	err = os.WriteFile(path, []byte("{}"), 0o600)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = server.reloadManifest()
+	if err == nil {
+		t.Fatalf("expected unsigned manifest to be refused")
+	}
+
+	response = server.signProposal(requester, testProposal(t, clientAddr))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+}
+
+func TestReloadIndependently(t *testing.T) {
+	key, operator := newTestOperator(t)
+
+	dir := t.TempDir()
+	err := os.Mkdir(filepath.Join(dir, "keys"), 0o700)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	files, err := keystore.NewFiles(nil, filepath.Join(dir, "keys"))
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	manifestPath := filepath.Join(dir, "manifest.json")
+	writeTestManifest(t, key, manifestPath, "baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa,512\n")
+	resolver := &testResolver{}
+	server, clientAddr := newTestServer(t, WithResolver(resolver), WithKeystore(files), WithPieceManifest(manifestPath, []peer.ID{operator}))
+
+	// The manifest is reloaded and the new key is not put in use while ID addresses cannot be resolved
+	resolver.err = errors.New("rpc is down")
+	err = os.WriteFile(filepath.Join(dir, "keys", "wallet"), []byte(testWalletKey+"\n"), 0o600)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	writeTestManifest(t, key, manifestPath, "baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa,256\n")
+	err = server.Reload(context.Background())
+	if err == nil {
+		t.Fatalf("expected the keys to fail to reload")
+	}
+
+	addrs, err := files.List(context.Background())
+	if err != nil || len(addrs) != 0 {
+		t.Fatalf("unexpected keystore addresses: %v %v", addrs, err)
+	}
+
+	response := server.signProposal("requester", testProposal(t, clientAddr))
+	if response.Code != model.Success {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	resolver.err = nil
+	err = server.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	addrs, err = files.List(context.Background())
+	if err != nil || len(addrs) != 1 {
+		t.Fatalf("unexpected keystore addresses: %v %v", addrs, err)
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/data-preservation-programs/filsigner-relayed/message"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/filecoin-project/go-address"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// walletAPIMessageTypes maps the wallet API message types other than deal proposals to the message type of the sign
+// protocol whose checks they go through. Lotus sends chain messages as their CID with the message in the meta, and
+// Boost signs deal status requests as unknown messages. Other types are never signed through the wallet API.
+var walletAPIMessageTypes = map[keystore.MsgType]string{
+	keystore.MTChainMsg: model.MessageTypeChainMessage,
+	keystore.MTUnknown:  model.MessageTypeDealStatusRequest,
+}
+
+// walletAPIAllows reports whether the message type is signed through the wallet API
+func (s *Server) walletAPIAllows(msgType keystore.MsgType) bool {
+	if len(s.walletAPITypes) == 0 {
+		return msgType == keystore.MTDealProposal
+	}
+
+	for _, allowed := range s.walletAPITypes {
+		if allowed == msgType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// responseError turns a failed signer response into the error returned to wallet API callers
+func responseError(response *model.SignerResponse) error {
+	message := model.StatusCodeString[response.Code] + ": " + response.Message
+	if response.Ticket != "" {
+		message += " (ticket " + response.Ticket + ")"
+	}
+
+	return errors.New(message)
+}
+
+func messageRecord(event audit.Event, requester peer.ID, addr address.Address, msgType string) audit.Record {
+	return audit.Record{
+		Event:       event,
+		Requester:   requester.String(),
+		Client:      addr.String(),
+		MessageType: msgType,
+	}
+}
+
+func (s *Server) WalletList(_ context.Context, requester peer.ID) ([]address.Address, error) {
+	if !s.isAllowed(requester) {
+		return nil, responseError(&model.SignerResponse{Code: model.UnauthorizedRequester, Message: "request is not from allowed requesters"})
+	}
+
+	seen := make(map[address.Address]struct{})
+	var addrs []address.Address
+	for _, key := range s.keys() {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		addrs = append(addrs, key)
+	}
+
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i].String() < addrs[j].String()
+	})
+	return addrs, nil
+}
+
+func (s *Server) WalletHas(_ context.Context, requester peer.ID, addr address.Address) (bool, error) {
+	if !s.isAllowed(requester) {
+		return false, responseError(&model.SignerResponse{Code: model.UnauthorizedRequester, Message: "request is not from allowed requesters"})
+	}
+
+	_, ok := s.keys()[addr]
+	return ok, nil
+}
+
+// WalletSign signs for a wallet API caller. Deal proposals and the other message types go through the same checks
+// as in the sign protocol, if the message type is allowed through the wallet API.
+func (s *Server) WalletSign(ctx context.Context, requester peer.ID, addr address.Address, data []byte, meta keystore.MsgMeta) (*filcrypto.Signature, error) {
+	if !s.isAllowed(requester) {
+		logging.Logger("server").Errorw("rejecting wallet API request", "remote", requester.String(), "code", model.UnauthorizedRequester)
+		return nil, responseError(&model.SignerResponse{Code: model.UnauthorizedRequester, Message: "request is not from allowed requesters"})
+	}
+
+	record := messageRecord(audit.Rejected, requester, addr, string(meta.Type))
+
+	if !s.walletAPIAllows(meta.Type) {
+		return nil, responseError(s.rejectRecord(record, model.MessageTypeNotAllowed, "message type "+string(meta.Type)+" is not allowed"))
+	}
+
+	keyAddr, ok := s.keys()[addr]
+	if !ok {
+		return nil, responseError(s.rejectRecord(record, model.WalletKeyNotFound, "private key not found for the address "+addr.String()))
+	}
+
+	if meta.Type == keystore.MTDealProposal {
+		return s.walletSignProposal(requester, keyAddr, data)
+	}
+
+	signType, ok := walletAPIMessageTypes[meta.Type]
+	if !ok {
+		return nil, responseError(s.rejectRecord(record, model.MessageTypeNotAllowed, "message type "+string(meta.Type)+" is not signed through the wallet API"))
+	}
+
+	record.MessageType = signType
+	if !s.messageTypeAllowed(addr, signType) {
+		return nil, responseError(s.rejectRecord(record, model.MessageTypeNotAllowed, "wallet "+addr.String()+" does not sign "+signType+" messages"))
+	}
+
+	if s.cosignRequired(addr) {
+		return nil, responseError(s.rejectRecord(record, model.CosignatureRequired, "wallets under the two-person rule only sign deal proposals"))
+	}
+
+	payload := data
+	if meta.Type == keystore.MTChainMsg {
+		payload = meta.Extra
+	}
+	code, err := messageTypes[signType].validate(s, requester, addr, payload)
+	if err != nil {
+		return nil, responseError(s.rejectRecord(record, code, err.Error()))
+	}
+
+	signingBytes, err := message.SigningBytes(signType, payload)
+	if err != nil {
+		return nil, responseError(s.rejectRecord(record, model.EncodeRequestError, err.Error()))
+	}
+
+	if !bytes.Equal(signingBytes, data) {
+		return nil, responseError(s.rejectRecord(record, model.DecodeRequestError, "the bytes to sign do not match the "+signType+" payload"))
+	}
+
+	signature, err := s.keystore.Sign(ctx, keyAddr, data, meta)
+	if err != nil {
+		return nil, responseError(s.rejectRecord(record, model.WalletSignError, err.Error()))
+	}
+
+	s.record(messageRecord(audit.Signed, requester, addr, signType))
+	return signature, nil
+}
+
+// walletSignProposal signs a deal proposal of the wallet API, which must be from the wallet of keyAddr
+func (s *Server) walletSignProposal(requester peer.ID, keyAddr address.Address, message []byte) (*filcrypto.Signature, error) {
+	// Undecodable proposals are rejected by signProposal
+	proposal := s.decodeProposal(message, 0)
+	if proposal != nil && s.keys()[proposal.Client] != keyAddr {
+		return nil, responseError(s.reject(requester, proposal, model.WalletKeyNotFound,
+			"proposal client "+proposal.Client.String()+" is not the signing wallet "+keyAddr.String()))
+	}
+
+	response := s.signProposal(requester, message)
+	if response.Code != model.Success {
+		return nil, responseError(response)
+	}
+
+	signature := new(filcrypto.Signature)
+	err := signature.UnmarshalBinary(response.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode signature")
+	}
+
+	return signature, nil
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/chainmsg"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/data-preservation-programs/filsigner-relayed/model"
+	"github.com/data-preservation-programs/filsigner-relayed/walletapi"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/jsign/go-filsigner/wallet"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWalletAPI(t *testing.T) {
+	server, clientAddr := newTestServer(t)
+	requester := peer.ID("requester")
+	server.allowedRequesters = []peer.ID{requester}
+	api := httptest.NewServer(walletapi.NewMux(server, map[string]peer.ID{"secret": requester}))
+	defer api.Close()
+
+	ctx := context.Background()
+	remote := keystore.NewLotus(api.URL+walletapi.Path, "secret", false)
+	addrs, err := remote.List(ctx)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if len(addrs) != 1 || addrs[0] != clientAddr {
+		t.Fatalf("unexpected addresses: %v", addrs)
+	}
+
+	proposalBytes := testProposal(t, clientAddr)
+	signature, err := remote.Sign(ctx, clientAddr, proposalBytes, keystore.MsgMeta{Type: keystore.MTDealProposal})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	signatureBytes, err := signature.MarshalBinary()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	valid, err := wallet.WalletVerify(clientAddr, proposalBytes, signatureBytes)
+	if err != nil || !valid {
+		t.Fatalf("signature is not valid: %v", err)
+	}
+
+	// Only deal proposals are allowed by default
+	_, err = remote.Sign(ctx, clientAddr, []byte("message"), keystore.MsgMeta{Type: keystore.MTUnknown})
+	if err == nil || !strings.Contains(err.Error(), "MessageTypeNotAllowed") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The bytes must be a deal proposal of the signing wallet
+	_, err = remote.Sign(ctx, clientAddr, []byte("message"), keystore.MsgMeta{Type: keystore.MTDealProposal})
+	if err == nil || !strings.Contains(err.Error(), "DecodeRequestError") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Unknown messages are deal status requests, which the wallet must be allowed to sign
+	server.walletAPITypes = []keystore.MsgType{keystore.MTUnknown}
+	_, err = remote.Sign(ctx, clientAddr, []byte("message"), keystore.MsgMeta{Type: keystore.MTUnknown})
+	if err == nil || !strings.Contains(err.Error(), "MessageTypeNotAllowed") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	server.messageTypes = map[address.Address][]string{clientAddr: {model.MessageTypeDealStatusRequest}}
+	_, err = remote.Sign(ctx, clientAddr, []byte("message"), keystore.MsgMeta{Type: keystore.MTUnknown})
+	if err == nil || !strings.Contains(err.Error(), "DecodeRequestError") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = remote.Sign(ctx, clientAddr, make([]byte, 16), keystore.MsgMeta{Type: keystore.MTUnknown})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	server.allowedRequesters = nil
+	_, err = remote.List(ctx)
+	if err == nil || !strings.Contains(err.Error(), "UnauthorizedRequester") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = keystore.NewLotus(api.URL+walletapi.Path, "wrong", false).List(ctx)
+	if err == nil {
+		t.Fatal("expected an error for a wrong token")
+	}
+}
+
+func TestWalletAPIChainMessage(t *testing.T) {
+	server, clientAddr := newTestServer(t, WithWalletAPITypes([]keystore.MsgType{keystore.MTChainMsg}))
+	requester := peer.ID("requester")
+	server.allowedRequesters = []peer.ID{requester}
+	market, err := address.NewIDAddress(5)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	server.messageTypes = map[address.Address][]string{clientAddr: {model.MessageTypeChainMessage}}
+	server.chainRules = map[address.Address][]chainmsg.Rule{clientAddr: {{To: market, Method: 2, MaxValue: big.NewInt(1000)}}}
+	api := httptest.NewServer(walletapi.NewMux(server, map[string]peer.ID{"secret": requester}))
+	defer api.Close()
+
+	remote := keystore.NewLotus(api.URL+walletapi.Path, "secret", false)
+	sign := func(msg *chainmsg.Message) error {
+		msgBytes, err := msg.Bytes()
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		msgCid, err := msg.Cid()
+		if err != nil {
+			t.Fatalf("err is not null: %v", err)
+		}
+
+		_, err = remote.Sign(context.Background(), clientAddr, msgCid.Bytes(), keystore.MsgMeta{Type: keystore.MTChainMsg, Extra: msgBytes})
+		return err
+	}
+
+	msg := &chainmsg.Message{
+		To:         market,
+		From:       clientAddr,
+		Value:      big.NewInt(1000),
+		GasLimit:   1000000,
+		GasFeeCap:  big.NewInt(100),
+		GasPremium: big.NewInt(10),
+		Method:     2,
+	}
+	err = sign(msg)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	// The wallet API applies the chain message rules of the wallet
+	msg.Method = 3
+	err = sign(msg)
+	if err == nil || !strings.Contains(err.Error(), "ChainMessageNotAllowed") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The signed bytes must be the CID of the checked message
+	msg.Method = 2
+	msgBytes, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	_, err = remote.Sign(context.Background(), clientAddr, []byte("other"), keystore.MsgMeta{Type: keystore.MTChainMsg, Extra: msgBytes})
+	if err == nil || !strings.Contains(err.Error(), "DecodeRequestError") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package verify
+
+import (
+	"context"
+	"github.com/data-preservation-programs/filsigner-relayed/chain"
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/jsign/go-filsigner/wallet"
+	"github.com/pkg/errors"
+)
+
+// Result is the outcome of verifying a client signature over a deal proposal
+type Result struct {
+	Valid       bool   `json:"valid"`
+	ProposalCID string `json:"proposalCid"`
+	Client      string `json:"client"`
+	// KeyAddress is the address the signature was verified against, resolved from Client if it is an ID address
+	KeyAddress string `json:"keyAddress,omitempty"`
+	KeyType    string `json:"keyType,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func keyType(sigType filcrypto.SigType) string {
+	switch sigType {
+	case filcrypto.SigTypeSecp256k1:
+		return "secp256k1"
+	case filcrypto.SigTypeBLS:
+		return "bls"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyAddress returns the key address to verify signatures of the client against.
+// ID addresses are resolved with the resolver, which may be nil if the client is already a key address.
+func KeyAddress(ctx context.Context, resolver chain.Resolver, client address.Address) (address.Address, error) {
+	if client.Protocol() == address.SECP256K1 || client.Protocol() == address.BLS {
+		return client, nil
+	}
+
+	if resolver == nil {
+		return address.Undef, errors.Errorf("cannot verify signatures for %s without an address resolver", client)
+	}
+
+	keyAddress, err := resolver.AccountKey(ctx, client)
+	if err != nil {
+		return address.Undef, errors.Wrapf(err, "failed to resolve key address of %s", client)
+	}
+
+	return keyAddress, nil
+}
+
+// Signature checks that the signature over the message was made by the key of the client address
+func Signature(ctx context.Context, resolver chain.Resolver, client address.Address, message []byte, signature *filcrypto.Signature) (address.Address, error) {
+	keyAddress, err := KeyAddress(ctx, resolver, client)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	// WalletVerify modifies secp256k1 signatures in place, so it gets its own copy
+	signatureBytes, err := signature.MarshalBinary()
+	if err != nil {
+		return keyAddress, errors.Wrap(err, "failed to marshal signature")
+	}
+
+	valid, err := wallet.WalletVerify(keyAddress, message, signatureBytes)
+	if err != nil {
+		return keyAddress, errors.Wrap(err, "failed to verify signature")
+	}
+
+	if !valid {
+		return keyAddress, errors.New("signature is not valid")
+	}
+
+	return keyAddress, nil
+}
+
+// Proposal verifies the client signature over the deal proposal.
+// An invalid signature is reported in the result, errors are only returned if the proposal cannot be processed.
+func Proposal(ctx context.Context, resolver chain.Resolver, proposal *filmarket.DealProposal, signature *filcrypto.Signature) (*Result, error) {
+	proposalBytes, err := cborutil.Dump(proposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal proposal")
+	}
+
+	proposalCid, err := proposal.Cid()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute proposal CID")
+	}
+
+	result := &Result{
+		ProposalCID: proposalCid.String(),
+		Client:      proposal.Client.String(),
+		KeyType:     keyType(signature.Type),
+	}
+
+	keyAddress, err := Signature(ctx, resolver, proposal.Client, proposalBytes, signature)
+	if keyAddress != address.Undef {
+		result.KeyAddress = keyAddress.String()
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	result.Valid = true
+	return result, nil
+}
+
+// ClientDealProposal verifies the client signature of a signed deal proposal
+func ClientDealProposal(ctx context.Context, resolver chain.Resolver, signed *filmarket.ClientDealProposal) (*Result, error) {
+	return Proposal(ctx, resolver, &signed.Proposal, &signed.ClientSignature)
+}
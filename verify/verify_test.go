@@ -0,0 +1,86 @@
+package verify
+
+import (
+	"context"
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/filecoin-project/go-state-types/big"
+	filmarket "github.com/filecoin-project/go-state-types/builtin/v9/market"
+	"github.com/ipfs/go-cid"
+	"github.com/jsign/go-filsigner/wallet"
+	"testing"
+)
+
+const testWalletKey = "7b2254797065223a22736563703235366b31222c22507269766174654b6579223a2244485a65316e7146756c7142382b44345a6167566f4f6654566d366e6f45415076414431705051446167343d227d"
+
+type staticResolver map[address.Address]address.Address
+
+func (r staticResolver) LookupID(_ context.Context, addr address.Address) (address.Address, error) {
+	return addr, nil
+}
+
+func (r staticResolver) AccountKey(_ context.Context, addr address.Address) (address.Address, error) {
+	return r[addr], nil
+}
+
+func TestProposal(t *testing.T) {
+	address.CurrentNetwork = address.Mainnet
+	keyAddr, err := wallet.PublicKey(testWalletKey)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	idAddr, err := address.NewIDAddress(1234)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	proposal := &filmarket.DealProposal{
+		PieceCID:             cid.MustParse("baga6ea4seaqgvktrw7sh3ypsuai76csagofcgnq6xlyulk5wjcunqsx6pg7dqfa"),
+		PieceSize:            256,
+		Client:               idAddr,
+		Provider:             address.TestAddress,
+		Label:                filmarket.EmptyDealLabel,
+		StoragePricePerEpoch: big.Zero(),
+		ProviderCollateral:   big.Zero(),
+		ClientCollateral:     big.Zero(),
+	}
+	proposalBytes, err := cborutil.Dump(proposal)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	signature, err := wallet.WalletSign(testWalletKey, proposalBytes)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	result, err := Proposal(context.Background(), nil, proposal, signature)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if result.Valid {
+		t.Fatalf("expected ID client to not be verifiable without resolver")
+	}
+
+	resolver := staticResolver{idAddr: keyAddr}
+	result, err = Proposal(context.Background(), resolver, proposal, signature)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if !result.Valid || result.KeyAddress != keyAddr.String() || result.KeyType != "secp256k1" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	proposal.PieceSize = 512
+	result, err = Proposal(context.Background(), resolver, proposal, signature)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	if result.Valid {
+		t.Fatalf("expected modified proposal to not be valid")
+	}
+}
@@ -0,0 +1,225 @@
+package walletapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"github.com/data-preservation-programs/filsigner-relayed/keystore"
+	"github.com/filecoin-project/go-address"
+	filcrypto "github.com/filecoin-project/go-state-types/crypto"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Path is the path the JSON-RPC API is served on, as on Lotus nodes
+const Path = "/rpc/v0"
+
+// JSON-RPC error codes. Handler errors use code 1, like the Lotus API.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeHandlerError   = 1
+)
+
+// Handler implements the wallet methods for an authenticated requester
+type Handler interface {
+	WalletList(ctx context.Context, requester peer.ID) ([]address.Address, error)
+	WalletHas(ctx context.Context, requester peer.ID, addr address.Address) (bool, error)
+	WalletSign(ctx context.Context, requester peer.ID, addr address.Address, message []byte, meta keystore.MsgMeta) (*filcrypto.Signature, error)
+}
+
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// ParseToken decodes an API token given as <requester peer ID>:<token>.
+// Calls made with the token are authorized and audited as the requester.
+func ParseToken(value string) (string, peer.ID, error) {
+	requester, token, ok := strings.Cut(value, ":")
+	if !ok || token == "" {
+		return "", "", errors.New("token must be given as <requester peer ID>:<token>")
+	}
+
+	requesterID, err := peer.Decode(requester)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to decode requester peer ID")
+	}
+
+	return token, requesterID, nil
+}
+
+// authenticate returns the requester of the bearer token of the request
+func authenticate(r *http.Request, tokens map[string]peer.ID) (peer.ID, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+
+	bearer := strings.TrimPrefix(header, "Bearer ")
+
+	var requester peer.ID
+	found := false
+	// Compare against every token so the response time does not tell how much of a token matched
+	for token, tokenRequester := range tokens {
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			requester = tokenRequester
+			found = true
+		}
+	}
+
+	return requester, found
+}
+
+// decodeParams decodes the positional params into the values, of which the first required ones must be given
+func decodeParams(params []json.RawMessage, required int, values ...any) error {
+	if len(params) < required || len(params) > len(values) {
+		return errors.Errorf("expected %d to %d params, got %d", required, len(values), len(params))
+	}
+
+	for i, param := range params {
+		err := json.Unmarshal(param, values[i])
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode param %d", i)
+		}
+	}
+
+	return nil
+}
+
+func call(ctx context.Context, handler Handler, requester peer.ID, req request) (any, *rpcError) {
+	switch req.Method {
+	case "Filecoin.WalletList":
+		err := decodeParams(req.Params, 0)
+		if err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+
+		addrs, err := handler.WalletList(ctx, requester)
+		if err != nil {
+			return nil, &rpcError{Code: codeHandlerError, Message: err.Error()}
+		}
+
+		return addrs, nil
+	case "Filecoin.WalletHas":
+		var addr address.Address
+		err := decodeParams(req.Params, 1, &addr)
+		if err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+
+		has, err := handler.WalletHas(ctx, requester, addr)
+		if err != nil {
+			return nil, &rpcError{Code: codeHandlerError, Message: err.Error()}
+		}
+
+		return has, nil
+	case "Filecoin.WalletSign":
+		var addr address.Address
+		var message []byte
+		// Full node clients do not send the metadata
+		meta := keystore.MsgMeta{Type: keystore.MTUnknown}
+		err := decodeParams(req.Params, 2, &addr, &message, &meta)
+		if err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+
+		signature, err := handler.WalletSign(ctx, requester, addr, message, meta)
+		if err != nil {
+			return nil, &rpcError{Code: codeHandlerError, Message: err.Error()}
+		}
+
+		return signature, nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method " + req.Method + " not found"}
+	}
+}
+
+func writeResponse(w http.ResponseWriter, resp response) {
+	resp.JSONRPC = "2.0"
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		logging.Logger("walletapi").Errorw("failed to write response", "error", err)
+	}
+}
+
+// NewMux serves the wallet methods of the handler to the holders of the tokens, which map to their requesters
+func NewMux(handler Handler, tokens map[string]peer.ID) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(Path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		requester, ok := authenticate(r, tokens)
+		if !ok {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		req := request{}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeResponse(w, response{Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+			return
+		}
+
+		logging.Logger("walletapi").Debugw("got wallet api call", "requester", requester.String(), "method", req.Method)
+		result, rpcErr := call(r.Context(), handler, requester, req)
+		if rpcErr != nil {
+			writeResponse(w, response{ID: req.ID, Error: rpcErr})
+			return
+		}
+
+		content, err := json.Marshal(result)
+		if err != nil {
+			writeResponse(w, response{ID: req.ID, Error: &rpcError{Code: codeHandlerError, Message: err.Error()}})
+			return
+		}
+
+		writeResponse(w, response{ID: req.ID, Result: content})
+	})
+	return mux
+}
+
+// Serve listens on the TCP address until the context is done
+func Serve(ctx context.Context, listenAddr string, mux *http.ServeMux) error {
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen for the wallet api")
+	}
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return errors.Wrap(err, "wallet api server failed")
+}
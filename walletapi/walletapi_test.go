@@ -0,0 +1,22 @@
+package walletapi
+
+import (
+	"testing"
+)
+
+func TestParseToken(t *testing.T) {
+	token, requester, err := ParseToken("12D3KooWHfQAbHNHQEEzqSFu6FoPuERbbVACkBD8xWSdtC6XQrWp:secret:with:colons")
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+	if token != "secret:with:colons" || requester.String() != "12D3KooWHfQAbHNHQEEzqSFu6FoPuERbbVACkBD8xWSdtC6XQrWp" {
+		t.Fatalf("unexpected token %s for %s", token, requester)
+	}
+
+	for _, value := range []string{"secret", "12D3KooWHfQAbHNHQEEzqSFu6FoPuERbbVACkBD8xWSdtC6XQrWp:", "notapeer:secret"} {
+		_, _, err = ParseToken(value)
+		if err == nil {
+			t.Fatalf("expected an error for %s", value)
+		}
+	}
+}
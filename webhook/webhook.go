@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"github.com/data-preservation-programs/filsigner-relayed/secret"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/jpillora/backoff"
+	"github.com/pkg/errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Filsigner-Signature"
+	EventHeader     = "X-Filsigner-Event"
+	DeliveryHeader  = "X-Filsigner-Delivery"
+)
+
+type Endpoint struct {
+	URL    string
+	Secret string
+	// SecretFile is read for the secret instead, again on every Reload
+	SecretFile string
+}
+
+// Notifier POSTs every audit record as a JSON event to the configured endpoints.
+// Events are written to a persistent outbox first and delivered in order, with retries, by Run.
+type Notifier struct {
+	endpoints []endpointOutbox
+	client    *http.Client
+	sequence  uint64
+	secretsMu sync.RWMutex
+	secrets   map[string]string
+}
+
+// deadDir is the directory of the outbox the events that cannot be read or decoded are moved to
+const deadDir = "dead"
+
+type endpointOutbox struct {
+	Endpoint
+	dir  string
+	wake chan struct{}
+}
+
+func NewNotifier(dir string, endpoints []Endpoint) (*Notifier, error) {
+	notifier := &Notifier{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		sequence: uint64(time.Now().UnixNano()),
+	}
+	for _, endpoint := range endpoints {
+		hash := sha256.Sum256([]byte(endpoint.URL))
+		outbox := endpointOutbox{
+			Endpoint: endpoint,
+			dir:      filepath.Join(dir, hex.EncodeToString(hash[:8])),
+			wake:     make(chan struct{}, 1),
+		}
+
+		err := os.MkdirAll(filepath.Join(outbox.dir, deadDir), 0o700)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create webhook outbox")
+		}
+
+		notifier.endpoints = append(notifier.endpoints, outbox)
+	}
+
+	err := notifier.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return notifier, nil
+}
+
+// Reload reads the secrets of the endpoints from their secret files again. It does nothing for a nil Notifier.
+func (n *Notifier) Reload() error {
+	if n == nil {
+		return nil
+	}
+
+	secrets := make(map[string]string, len(n.endpoints))
+	for _, outbox := range n.endpoints {
+		secrets[outbox.URL] = outbox.Secret
+		if outbox.SecretFile != "" {
+			value, err := secret.ReadString(outbox.SecretFile)
+			if err != nil {
+				return errors.Wrap(err, "failed to read webhook secret")
+			}
+			secrets[outbox.URL] = value
+		}
+	}
+
+	n.secretsMu.Lock()
+	n.secrets = secrets
+	n.secretsMu.Unlock()
+	return nil
+}
+
+func (n *Notifier) secret(url string) string {
+	n.secretsMu.RLock()
+	defer n.secretsMu.RUnlock()
+	return n.secrets[url]
+}
+
+// Sign returns the value of the signature header for the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify queues the record for delivery to every endpoint. A nil Notifier discards the record.
+func (n *Notifier) Notify(record audit.Record) error {
+	if n == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode webhook event")
+	}
+
+	name := fmt.Sprintf("%020d.json", atomic.AddUint64(&n.sequence, 1))
+	for _, outbox := range n.endpoints {
+		tmp := filepath.Join(outbox.dir, name+".tmp")
+		err = os.WriteFile(tmp, body, 0o600)
+		if err != nil {
+			return errors.Wrap(err, "failed to write webhook event to outbox")
+		}
+
+		err = os.Rename(tmp, filepath.Join(outbox.dir, name))
+		if err != nil {
+			return errors.Wrap(err, "failed to write webhook event to outbox")
+		}
+
+		select {
+		case outbox.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Run delivers the queued events until the context is done
+func (n *Notifier) Run(ctx context.Context) {
+	for _, outbox := range n.endpoints {
+		outbox := outbox
+		go n.deliverAll(ctx, outbox)
+	}
+
+	<-ctx.Done()
+}
+
+func (n *Notifier) deliverAll(ctx context.Context, outbox endpointOutbox) {
+	log := logging.Logger("webhook").With("endpoint", outbox.URL)
+	waitTime := &backoff.Backoff{
+		Min: time.Second,
+		Max: 5 * time.Minute,
+	}
+
+	for {
+		entries, err := os.ReadDir(outbox.dir)
+		if err != nil {
+			log.Errorw("failed to read webhook outbox", "error", err)
+		}
+
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".json") {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Strings(names)
+
+		failed := false
+		for _, name := range names {
+			body, record, err := readEvent(filepath.Join(outbox.dir, name))
+			if err != nil {
+				// Retrying would not help, and would hold back every later event
+				log.Errorw("moving undeliverable webhook event to the dead letter directory", "event", name, "error", err)
+				err = os.Rename(filepath.Join(outbox.dir, name), filepath.Join(outbox.dir, deadDir, name))
+				if err != nil {
+					log.Errorw("failed to move undeliverable webhook event", "event", name, "error", err)
+					failed = true
+					break
+				}
+				continue
+			}
+
+			err = n.deliver(ctx, outbox, name, body, record)
+			if err != nil {
+				log.Warnw("failed to deliver webhook event, will retry", "event", name, "error", err)
+				failed = true
+				break
+			}
+
+			waitTime.Reset()
+		}
+
+		wait := time.Hour
+		if failed {
+			wait = waitTime.Duration()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-outbox.wake:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// readEvent reads the event at path from the outbox
+func readEvent(path string) ([]byte, audit.Record, error) {
+	record := audit.Record{}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, record, errors.Wrap(err, "failed to read webhook event")
+	}
+
+	err = json.Unmarshal(body, &record)
+	if err != nil {
+		return nil, record, errors.Wrap(err, "failed to decode webhook event")
+	}
+
+	return body, record, nil
+}
+
+func (n *Notifier) deliver(ctx context.Context, outbox endpointOutbox, name string, body []byte, record audit.Record) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, outbox.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook request")
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, string(record.Event))
+	request.Header.Set(DeliveryHeader, strings.TrimSuffix(name, ".json"))
+	if key := n.secret(outbox.URL); key != "" {
+		request.Header.Set(SignatureHeader, Sign(key, body))
+	}
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to post webhook event")
+	}
+	response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("webhook endpoint returned status %d", response.StatusCode)
+	}
+
+	return errors.Wrap(os.Remove(filepath.Join(outbox.dir, name)), "failed to remove delivered webhook event")
+}
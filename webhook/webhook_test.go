@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/data-preservation-programs/filsigner-relayed/audit"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotifierRetriesAndSigns(t *testing.T) {
+	var attempts int32
+	received := make(chan audit.Record, 1)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		record := audit.Record{}
+		_ = json.Unmarshal(body, &record)
+		received <- record
+	}))
+	defer endpoint.Close()
+
+	notifier, err := NewNotifier(t.TempDir(), []Endpoint{{URL: endpoint.URL, Secret: "secret"}})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = notifier.Notify(audit.Record{Event: audit.Signed, Client: "f01234"})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go notifier.Run(ctx)
+
+	select {
+	case record := <-received:
+		if record.Event != audit.Signed || record.Client != "f01234" {
+			t.Fatalf("unexpected event: %v", record)
+		}
+	case <-ctx.Done():
+		t.Fatalf("event was not delivered")
+	}
+
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Fatalf("expected one retry, got %d attempts", attempts)
+	}
+}
+
+func TestNotifierSkipsUndecodableEvents(t *testing.T) {
+	received := make(chan audit.Record, 1)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record := audit.Record{}
+		_ = json.NewDecoder(r.Body).Decode(&record)
+		received <- record
+	}))
+	defer endpoint.Close()
+
+	notifier, err := NewNotifier(t.TempDir(), []Endpoint{{URL: endpoint.URL}})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	// The corrupt event sorts before the events queued by Notify
+	outboxDir := notifier.endpoints[0].dir
+	err = os.WriteFile(filepath.Join(outboxDir, "00000000000000000000.json"), []byte("{corrupt"), 0o600)
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	err = notifier.Notify(audit.Record{Event: audit.Signed, Client: "f01234"})
+	if err != nil {
+		t.Fatalf("err is not null: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go notifier.Run(ctx)
+
+	select {
+	case record := <-received:
+		if record.Client != "f01234" {
+			t.Fatalf("unexpected event: %v", record)
+		}
+	case <-ctx.Done():
+		t.Fatalf("event was not delivered")
+	}
+
+	_, err = os.Stat(filepath.Join(outboxDir, deadDir, "00000000000000000000.json"))
+	if err != nil {
+		t.Fatalf("expected the corrupt event in the dead letter directory: %v", err)
+	}
+}